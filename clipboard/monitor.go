@@ -2,8 +2,10 @@ package clipboard
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"log"
+	"regexp"
 	"sync"
 	"time"
 
@@ -16,12 +18,186 @@ const (
 	clipboardPollInterval = 500 * time.Millisecond
 )
 
+// eventsChanBufferSize bounds how many undelivered clipboard changes Events() will queue
+const eventsChanBufferSize = 16
+
+// recentWriteHashBufferSize bounds the ring buffer of hashes written by SetContent,
+// used to recognize and skip TraceTrim's own clipboard writes on the next poll.
+const recentWriteHashBufferSize = 8
+
 // Monitor handles clipboard monitoring across platforms
 type Monitor struct {
-	platform    Platform
-	stopChan    chan struct{}
-	lastContent string
-	mutex       sync.RWMutex // Protects lastContent
+	platform          Platform
+	stopChan          chan struct{}
+	lastContent       string
+	mutex             sync.RWMutex // Protects lastContent, writeSequence and recentWriteHashes
+	events            chan models.ClipboardContent
+	writeSequence     uint64
+	recentWriteHashes [][32]byte // ring buffer of the last SHA-256 hashes written by SetContent
+
+	subMutex           sync.Mutex // Protects subscribers and nextSubscriptionID
+	subscribers        map[int]*subscription
+	nextSubscriptionID int
+
+	formatMutex      sync.Mutex // Protects acceptedFormats and lastFormatHashes
+	acceptedFormats  []string
+	lastFormatHashes map[string][32]byte
+
+	// intervalChan carries poll interval changes to a running watchTicker loop;
+	// see SetPollInterval.
+	intervalChan chan time.Duration
+}
+
+// defaultAcceptedFormats is dispatched when SetAcceptedFormats hasn't been called,
+// matching the monitor's historical text-only behavior.
+var defaultAcceptedFormats = []string{"text/plain"}
+
+// SetAcceptedFormats restricts which MIME types pollOnce dispatches to the
+// callback, Events(), and subscribers. Formats not listed here are still
+// readable via the platform's GetContentTyped, but won't trigger the
+// clean-and-write-back loop. An empty or nil list resets to the default of
+// ["text/plain"].
+func (m *Monitor) SetAcceptedFormats(formats []string) {
+	m.formatMutex.Lock()
+	defer m.formatMutex.Unlock()
+	m.acceptedFormats = formats
+}
+
+// isFormatAccepted reports whether format should be dispatched, per the
+// current AcceptedFormats configuration.
+func (m *Monitor) isFormatAccepted(format string) bool {
+	m.formatMutex.Lock()
+	accepted := m.acceptedFormats
+	m.formatMutex.Unlock()
+
+	if len(accepted) == 0 {
+		accepted = defaultAcceptedFormats
+	}
+	for _, f := range accepted {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriberChanBufferSize bounds how many undelivered changes a Subscribe() channel will queue
+const subscriberChanBufferSize = 16
+
+// subscription pairs a subscriber's filter with the channel it receives matching changes on
+type subscription struct {
+	filter Filter
+	ch     chan models.ClipboardContent
+}
+
+// Filter narrows which clipboard changes a Subscribe() consumer receives, letting
+// cheap prefilters (minimum length, format, a content regex) run before heavier
+// downstream processing like stack-trace parsing.
+type Filter struct {
+	// MinLength rejects content shorter than this many bytes. Zero means no minimum.
+	MinLength int
+
+	// Formats restricts matches to these MIME types. Empty means any format.
+	Formats []string
+
+	// Regex, if set, must match the clipboard content.
+	Regex *regexp.Regexp
+
+	// ExcludeSelfWrites drops content TraceTrim itself wrote back to the clipboard.
+	ExcludeSelfWrites bool
+}
+
+// matches reports whether content satisfies the filter
+func (f Filter) matches(content models.ClipboardContent) bool {
+	if f.ExcludeSelfWrites && content.Origin == models.ClipboardOriginSelf {
+		return false
+	}
+
+	if f.MinLength > 0 && len(content.Content) < f.MinLength {
+		return false
+	}
+
+	if len(f.Formats) > 0 {
+		formatAllowed := false
+		for _, format := range f.Formats {
+			if format == content.Format {
+				formatAllowed = true
+				break
+			}
+		}
+		if !formatAllowed {
+			return false
+		}
+	}
+
+	if f.Regex != nil && !f.Regex.MatchString(content.Content) {
+		return false
+	}
+
+	return true
+}
+
+// Subscribe registers a new consumer of clipboard changes matching filter, returning
+// a subscription id (for Unsubscribe) and a channel of matching changes. Multiple
+// subscribers - the clipboard cleaner, a logger, an HTTP/WebSocket bridge - can all
+// consume the same underlying poll/notification stream without each one polling
+// independently.
+func (m *Monitor) Subscribe(filter Filter) (int, <-chan models.ClipboardContent) {
+	m.subMutex.Lock()
+	defer m.subMutex.Unlock()
+
+	if m.subscribers == nil {
+		m.subscribers = make(map[int]*subscription)
+	}
+
+	m.nextSubscriptionID++
+	id := m.nextSubscriptionID
+	ch := make(chan models.ClipboardContent, subscriberChanBufferSize)
+	m.subscribers[id] = &subscription{filter: filter, ch: ch}
+
+	return id, ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel. It is a no-op if id is unknown.
+func (m *Monitor) Unsubscribe(id int) {
+	m.subMutex.Lock()
+	defer m.subMutex.Unlock()
+
+	sub, ok := m.subscribers[id]
+	if !ok {
+		return
+	}
+
+	delete(m.subscribers, id)
+	close(sub.ch)
+}
+
+// dispatchToSubscribers fans content out to every subscriber whose filter matches
+func (m *Monitor) dispatchToSubscribers(content models.ClipboardContent) {
+	m.subMutex.Lock()
+	defer m.subMutex.Unlock()
+
+	for _, sub := range m.subscribers {
+		if !sub.filter.matches(content) {
+			continue
+		}
+
+		select {
+		case sub.ch <- content:
+		default:
+			log.Printf("Subscriber channel full, dropping clipboard change notification")
+		}
+	}
+}
+
+// ChangeNotifier is an optional interface a Platform can implement to deliver
+// native clipboard-change notifications instead of Monitor falling back to
+// ticker-based polling. A send on the returned channel means "re-read the
+// clipboard now"; the channel is closed (or NotifyChanges returns an error)
+// when native notifications cannot be established, in which case the caller
+// should fall back to polling.
+type ChangeNotifier interface {
+	NotifyChanges(ctx context.Context) (<-chan struct{}, error)
 }
 
 // Platform interface abstracts platform-specific clipboard operations
@@ -29,6 +205,66 @@ type Platform interface {
 	GetContent() (string, error)
 	SetContent(content string) error
 	GetName() string
+
+	// GetContentAs retrieves clipboard content in a specific format, identified by
+	// a MIME type (e.g. "text/plain", "text/html", "image/png") or platform-native
+	// format name. Implementations should return an error if the format is not
+	// currently present on the clipboard.
+	GetContentAs(format string) ([]byte, error)
+
+	// SetContentAs writes data to the clipboard tagged as the given format.
+	SetContentAs(format string, data []byte) error
+
+	// AvailableFormats lists the formats currently present on the clipboard.
+	AvailableFormats() ([]string, error)
+
+	// GetContentTyped returns every representation the clipboard currently
+	// advertises, each tagged with its MIME type on ClipboardContent.Format.
+	// Implementations that can't enumerate multiple representations should
+	// return a single-element slice for "text/plain".
+	GetContentTyped() ([]models.ClipboardContent, error)
+}
+
+// contentTypedFromFormats is the default GetContentTyped implementation shared by
+// every platform that already implements AvailableFormats and GetContentAs: it
+// queries the clipboard's advertised formats, fetches each one, and decodes
+// text-like MIME types into ClipboardContent.Content while every format's raw
+// bytes are preserved in ClipboardContent.Raw.
+func contentTypedFromFormats(p Platform) ([]models.ClipboardContent, error) {
+	formats, err := p.AvailableFormats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clipboard formats: %w", err)
+	}
+
+	var contents []models.ClipboardContent
+	for _, format := range formats {
+		data, err := p.GetContentAs(format)
+		if err != nil {
+			// Advertised but no longer retrievable (the clipboard changed
+			// mid-enumeration, or the platform can't actually produce it) -
+			// skip rather than fail the whole call.
+			continue
+		}
+
+		content := models.ClipboardContent{Format: format, Raw: data}
+		if isTextLikeFormat(format) {
+			content.Content = string(data)
+		}
+		contents = append(contents, content)
+	}
+
+	return contents, nil
+}
+
+// isTextLikeFormat reports whether format's payload should also be exposed as
+// decoded text via ClipboardContent.Content, rather than only as raw bytes.
+func isTextLikeFormat(format string) bool {
+	switch format {
+	case "text/plain", "text/html", "text/rtf", "":
+		return true
+	default:
+		return false
+	}
 }
 
 // standardPlatform implements Platform interface using golang.design/x/clipboard
@@ -67,6 +303,74 @@ func (s *standardPlatform) SetContent(content string) error {
 	return nil
 }
 
+// standardClipboardFormat maps a MIME type to the golang.design/x/clipboard format constant
+func standardClipboardFormat(format string) (clipboard.Format, error) {
+	switch format {
+	case "text/plain", "":
+		return clipboard.FmtText, nil
+	case "image/png":
+		return clipboard.FmtImage, nil
+	default:
+		return 0, fmt.Errorf("unsupported clipboard format: %s", format)
+	}
+}
+
+// GetContentAs retrieves clipboard content for the given format
+func (s *standardPlatform) GetContentAs(format string) ([]byte, error) {
+	if err := clipboard.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+
+	fmtID, err := standardClipboardFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	data := clipboard.Read(fmtID)
+	if data == nil {
+		return nil, fmt.Errorf("no %s data available in clipboard", format)
+	}
+
+	return data, nil
+}
+
+// SetContentAs writes data to the clipboard tagged as the given format
+func (s *standardPlatform) SetContentAs(format string, data []byte) error {
+	if err := clipboard.Init(); err != nil {
+		return fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+
+	fmtID, err := standardClipboardFormat(format)
+	if err != nil {
+		return err
+	}
+
+	clipboard.Write(fmtID, data)
+	return nil
+}
+
+// AvailableFormats reports the formats the standard backend can detect
+func (s *standardPlatform) AvailableFormats() ([]string, error) {
+	if err := clipboard.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize clipboard: %w", err)
+	}
+
+	var formats []string
+	if clipboard.Read(clipboard.FmtText) != nil {
+		formats = append(formats, "text/plain")
+	}
+	if clipboard.Read(clipboard.FmtImage) != nil {
+		formats = append(formats, "image/png")
+	}
+
+	return formats, nil
+}
+
+// GetContentTyped returns every representation the standard backend can detect
+func (s *standardPlatform) GetContentTyped() ([]models.ClipboardContent, error) {
+	return contentTypedFromFormats(s)
+}
+
 // NewMonitor creates a new clipboard monitor for the current platform
 func NewMonitor() (*Monitor, error) {
 	platform, err := getPlatform()
@@ -75,20 +379,47 @@ func NewMonitor() (*Monitor, error) {
 	}
 
 	return &Monitor{
-		platform: platform,
-		stopChan: make(chan struct{}),
+		platform:     platform,
+		stopChan:     make(chan struct{}),
+		events:       make(chan models.ClipboardContent, eventsChanBufferSize),
+		intervalChan: make(chan time.Duration, 1),
 	}, nil
 }
 
+// SetPollInterval changes the interval used by a running ticker-driven polling
+// loop (see watchTicker), letting a config hot reload take effect without
+// restarting monitoring. It's a no-op when monitoring is driven by native
+// change notifications instead of polling, or before StartMonitoring* has
+// been called.
+func (m *Monitor) SetPollInterval(interval time.Duration) {
+	select {
+	case m.intervalChan <- interval:
+	default:
+		// A pending change hasn't been picked up yet; replace it with the latest.
+		select {
+		case <-m.intervalChan:
+		default:
+		}
+		m.intervalChan <- interval
+	}
+}
+
+// Events returns a channel of clipboard changes observed while monitoring runs,
+// letting consumers subscribe to the same stream as callback without polling
+// independently. The channel is not closed when monitoring stops.
+func (m *Monitor) Events() <-chan models.ClipboardContent {
+	return m.events
+}
+
 // StartMonitoring begins monitoring the clipboard for changes with default interval
 func (m *Monitor) StartMonitoring(ctx context.Context, callback func(models.ClipboardContent, *Monitor)) error {
 	return m.StartMonitoringWithInterval(ctx, clipboardPollInterval, callback)
 }
 
-// StartMonitoringWithInterval begins monitoring the clipboard for changes with custom interval
+// StartMonitoringWithInterval begins monitoring the clipboard for changes with custom interval.
+// When the platform implements ChangeNotifier, native change events drive the loop; otherwise
+// (or if the listener fails to initialize) it falls back to polling at the given interval.
 func (m *Monitor) StartMonitoringWithInterval(ctx context.Context, interval time.Duration, callback func(models.ClipboardContent, *Monitor)) error {
-	log.Printf("Starting clipboard monitoring on %s with %v interval", m.platform.GetName(), interval)
-
 	// Get initial content
 	initialContent, err := m.platform.GetContent()
 	if err != nil {
@@ -98,7 +429,41 @@ func (m *Monitor) StartMonitoringWithInterval(ctx context.Context, interval time
 	m.lastContent = initialContent
 	m.mutex.Unlock()
 
-	// Start monitoring loop
+	if notifier, ok := m.platform.(ChangeNotifier); ok {
+		changes, err := notifier.NotifyChanges(ctx)
+		if err == nil {
+			log.Printf("Starting clipboard monitoring on %s with native change notifications", m.platform.GetName())
+			return m.watchNotifications(ctx, changes, callback)
+		}
+		log.Printf("Native clipboard notifications unavailable on %s (%v), falling back to polling", m.platform.GetName(), err)
+	}
+
+	log.Printf("Starting clipboard monitoring on %s with %v polling interval", m.platform.GetName(), interval)
+	return m.watchTicker(ctx, interval, callback)
+}
+
+// watchNotifications drives the callback loop from a native change-notification channel
+func (m *Monitor) watchNotifications(ctx context.Context, changes <-chan struct{}, callback func(models.ClipboardContent, *Monitor)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Stopping clipboard monitoring")
+			return nil
+		case <-m.stopChan:
+			log.Println("Stopping clipboard monitoring")
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				log.Println("Clipboard change listener closed, stopping monitoring")
+				return nil
+			}
+			m.pollOnce(callback)
+		}
+	}
+}
+
+// watchTicker drives the callback loop by polling the platform at a fixed interval
+func (m *Monitor) watchTicker(ctx context.Context, interval time.Duration, callback func(models.ClipboardContent, *Monitor)) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -110,31 +475,158 @@ func (m *Monitor) StartMonitoringWithInterval(ctx context.Context, interval time
 		case <-m.stopChan:
 			log.Println("Stopping clipboard monitoring")
 			return nil
+		case newInterval := <-m.intervalChan:
+			ticker.Reset(newInterval)
+			log.Printf("Clipboard poll interval changed to %v", newInterval)
 		case <-ticker.C:
-			content, err := m.platform.GetContent()
-			if err != nil {
-				log.Printf("Error getting clipboard content: %v", err)
-				continue
-			}
+			m.pollOnce(callback)
+		}
+	}
+}
 
-			// Check if content has changed (with proper locking)
-			m.mutex.Lock()
-			contentChanged := content != m.lastContent && content != ""
-			if contentChanged {
-				m.lastContent = content
-			}
-			m.mutex.Unlock()
-
-			if contentChanged {
-				clipboardContent := models.ClipboardContent{
-					Content:   content,
-					Timestamp: time.Now(),
-					Format:    "text/plain",
-				}
-				callback(clipboardContent, m)
-			}
+// pollOnce reads the clipboard once, and if the content changed, dispatches it to the
+// callback and to any Events() subscriber.
+func (m *Monitor) pollOnce(callback func(models.ClipboardContent, *Monitor)) {
+	content, err := m.platform.GetContent()
+	if err != nil {
+		log.Printf("Error getting clipboard content: %v", err)
+		return
+	}
+
+	// Check if content has changed (with proper locking)
+	m.mutex.Lock()
+	contentChanged := content != m.lastContent && content != ""
+	if contentChanged {
+		m.lastContent = content
+	}
+	isSelfWrite := contentChanged && m.wasRecentlyWrittenLocked(content)
+	m.mutex.Unlock()
+
+	// Non-text formats (images, RTF) are tracked independently of the text
+	// comparison above, since the clipboard's text representation can stay
+	// the same while its image representation changes.
+	m.pollNonTextFormats(callback)
+
+	if !contentChanged || !m.isFormatAccepted("text/plain") {
+		return
+	}
+
+	origin := models.ClipboardOriginExternal
+	if isSelfWrite {
+		origin = models.ClipboardOriginSelf
+	}
+
+	clipboardContent := models.ClipboardContent{
+		Content:   content,
+		Timestamp: time.Now(),
+		Format:    "text/plain",
+		Origin:    origin,
+	}
+
+	// The legacy callback and Events() channel exist to drive TraceTrim's own
+	// clean-and-write-back loop, so they never see our own writes. Subscribe()
+	// consumers decide for themselves via Filter.ExcludeSelfWrites.
+	if isSelfWrite {
+		log.Printf("Skipping clipboard content written by a previous SetContent call")
+	} else {
+		callback(clipboardContent, m)
+
+		select {
+		case m.events <- clipboardContent:
+		default:
+			log.Printf("Events channel full, dropping clipboard change notification")
+		}
+	}
+
+	m.dispatchToSubscribers(clipboardContent)
+}
+
+// pollNonTextFormats dispatches any accepted, changed non-text/plain clipboard
+// representation (image/png, text/rtf, etc.) via GetContentTyped, letting
+// callbacks that opted into AcceptedFormats see them even though the
+// text-based change detection above never would. It's a no-op unless
+// SetAcceptedFormats has been called with something beyond the default
+// ["text/plain"], so it costs nothing for callers who never opt in.
+func (m *Monitor) pollNonTextFormats(callback func(models.ClipboardContent, *Monitor)) {
+	m.formatMutex.Lock()
+	accepted := m.acceptedFormats
+	m.formatMutex.Unlock()
+
+	hasNonTextFormat := false
+	for _, format := range accepted {
+		if format != "text/plain" {
+			hasNonTextFormat = true
+			break
+		}
+	}
+	if !hasNonTextFormat {
+		return
+	}
+
+	typed, err := m.platform.GetContentTyped()
+	if err != nil {
+		log.Printf("Error getting typed clipboard content: %v", err)
+		return
+	}
+
+	for _, content := range typed {
+		if content.Format == "text/plain" || !m.isFormatAccepted(content.Format) {
+			continue
+		}
+
+		hash := sha256.Sum256(content.Raw)
+
+		m.formatMutex.Lock()
+		if m.lastFormatHashes == nil {
+			m.lastFormatHashes = make(map[string][32]byte)
+		}
+		previous, seen := m.lastFormatHashes[content.Format]
+		changed := !seen || previous != hash
+		if changed {
+			m.lastFormatHashes[content.Format] = hash
+		}
+		m.formatMutex.Unlock()
+
+		if !changed {
+			continue
+		}
+
+		content.Timestamp = time.Now()
+		content.Origin = models.ClipboardOriginExternal
+
+		callback(content, m)
+
+		select {
+		case m.events <- content:
+		default:
+			log.Printf("Events channel full, dropping clipboard change notification")
+		}
+
+		m.dispatchToSubscribers(content)
+	}
+}
+
+// wasRecentlyWrittenLocked reports whether content matches one of the last values
+// written via SetContent. Callers must hold m.mutex.
+func (m *Monitor) wasRecentlyWrittenLocked(content string) bool {
+	hash := sha256.Sum256([]byte(content))
+	for _, recent := range m.recentWriteHashes {
+		if recent == hash {
+			return true
 		}
 	}
+	return false
+}
+
+// recordWriteLocked appends a write's hash to the ring buffer and bumps writeSequence.
+// Callers must hold m.mutex.
+func (m *Monitor) recordWriteLocked(content string) {
+	hash := sha256.Sum256([]byte(content))
+	m.recentWriteHashes = append(m.recentWriteHashes, hash)
+	if len(m.recentWriteHashes) > recentWriteHashBufferSize {
+		m.recentWriteHashes = m.recentWriteHashes[len(m.recentWriteHashes)-recentWriteHashBufferSize:]
+	}
+	m.writeSequence++
 }
 
 // Stop stops the clipboard monitoring
@@ -150,7 +642,16 @@ func (m *Monitor) GetCurrentContent() (string, error) {
 	return m.platform.GetContent()
 }
 
-// SetContent sets the clipboard content
+// SetContent sets the clipboard content, recording its hash so the next poll
+// recognizes it as our own write instead of dispatching it as a new external change.
 func (m *Monitor) SetContent(content string) error {
-	return m.platform.SetContent(content)
+	if err := m.platform.SetContent(content); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	m.recordWriteLocked(content)
+	m.mutex.Unlock()
+
+	return nil
 }
@@ -51,7 +51,11 @@ import "C"
 
 import (
 	"fmt"
+	"os/exec"
+	"regexp"
 	"unsafe"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
 )
 
 // getPlatform returns the appropriate platform implementation for macOS
@@ -90,3 +94,128 @@ func (d *darwinPlatform) SetContent(content string) error {
 
 	return nil
 }
+
+// pasteboardUTI maps a MIME type to the macOS pasteboard uniform type identifier
+// used by osascript when negotiating a non-plain-text representation.
+func pasteboardUTI(format string) (string, error) {
+	switch format {
+	case "text/plain", "":
+		return "public.utf8-plain-text", nil
+	case "text/html":
+		return "public.html", nil
+	case "text/rtf":
+		return "public.rtf", nil
+	case "image/png":
+		return "public.png", nil
+	case "image/tiff":
+		return "public.tiff", nil
+	default:
+		return "", fmt.Errorf("unsupported clipboard format: %s", format)
+	}
+}
+
+// darwinClassToMIME reverse-maps the four-character AppleScript class code
+// "clipboard info" reports back to the MIME type GetContentAs/SetContentAs
+// expect, the inverse of utiClassCode.
+func darwinClassToMIME(code string) (string, bool) {
+	switch code {
+	case "utf8":
+		return "text/plain", true
+	case "HTML":
+		return "text/html", true
+	case "RTF ":
+		return "text/rtf", true
+	case "PNGf":
+		return "image/png", true
+	case "TIFF":
+		return "image/tiff", true
+	default:
+		return "", false
+	}
+}
+
+// GetContentAs retrieves clipboard content for a given MIME type, reading text/plain
+// through the Cocoa pasteboard and other formats via osascript
+func (d *darwinPlatform) GetContentAs(format string) ([]byte, error) {
+	if format == "text/plain" || format == "" {
+		content, err := d.GetContent()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(content), nil
+	}
+
+	uti, err := pasteboardUTI(format)
+	if err != nil {
+		return nil, err
+	}
+
+	script := fmt.Sprintf(`the clipboard as «class %s»`, utiClassCode(uti))
+	output, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard as %s: %w", format, err)
+	}
+
+	return output, nil
+}
+
+// SetContentAs writes data to the clipboard tagged with the given MIME type
+func (d *darwinPlatform) SetContentAs(format string, data []byte) error {
+	if format == "text/plain" || format == "" {
+		return d.SetContent(string(data))
+	}
+
+	if _, err := pasteboardUTI(format); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("setting non-text clipboard formats on macOS is not yet supported: %s", format)
+}
+
+// darwinClipboardInfoClassPattern extracts each AppleScript class code from
+// "clipboard info" output, e.g. "«class utf8», «class RTF »".
+var darwinClipboardInfoClassPattern = regexp.MustCompile(`«class (.{4})»`)
+
+// AvailableFormats lists the pasteboard types currently advertised via osascript,
+// translated from AppleScript class codes to MIME types.
+func (d *darwinPlatform) AvailableFormats() ([]string, error) {
+	output, err := exec.Command("osascript", "-e", "clipboard info").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clipboard formats: %w", err)
+	}
+
+	var formats []string
+	seen := make(map[string]bool)
+	for _, match := range darwinClipboardInfoClassPattern.FindAllStringSubmatch(string(output), -1) {
+		format, ok := darwinClassToMIME(match[1])
+		if !ok || seen[format] {
+			continue
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+
+	return formats, nil
+}
+
+// GetContentTyped returns every representation the pasteboard currently advertises
+func (d *darwinPlatform) GetContentTyped() ([]models.ClipboardContent, error) {
+	return contentTypedFromFormats(d)
+}
+
+// utiClassCode returns the four-character AppleScript class code for a UTI,
+// as used by "the clipboard as «class ....»".
+func utiClassCode(uti string) string {
+	switch uti {
+	case "public.html":
+		return "HTML"
+	case "public.rtf":
+		return "RTF "
+	case "public.png":
+		return "PNGf"
+	case "public.tiff":
+		return "TIFF"
+	default:
+		return "utf8"
+	}
+}
@@ -4,102 +4,257 @@
 package clipboard
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"log"
+	"os"
 	"os/exec"
 	"strings"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
 )
 
-// linuxPlatform implements Platform interface for Linux
-type linuxPlatform struct{}
+// unixTool describes how to shell out to a Unix clipboard utility: the binary to
+// invoke, the fixed arguments for reading and writing the clipboard, and the flag
+// used to negotiate a specific MIME type/target (empty if the tool only supports
+// plain text). New tools can be supported by registering another entry in
+// preferredUnixTools.
+type unixTool struct {
+	name      string
+	bin       string
+	readArgs  []string
+	writeArgs []string
+	typeFlag  string
+}
 
-// getPlatform returns the appropriate platform implementation for Linux
-func getPlatform() (Platform, error) {
-	return &linuxPlatform{}, nil
+// preferredUnixTools is tried in order; the first tool whose binary is found on
+// PATH is selected by getPlatform. Wayland-native tools are listed first so a
+// Wayland session prefers wl-clipboard over XWayland-shimmed X11 tools.
+var preferredUnixTools = []unixTool{
+	{
+		name:      "wl-clipboard",
+		bin:       "wl-paste",
+		readArgs:  []string{"--no-newline"},
+		writeArgs: []string{},
+		typeFlag:  "--type",
+	},
+	{
+		name:      "xclip",
+		bin:       "xclip",
+		readArgs:  []string{"-selection", "clipboard", "-o"},
+		writeArgs: []string{"-selection", "clipboard", "-i"},
+		typeFlag:  "-t",
+	},
+	{
+		name:      "xsel",
+		bin:       "xsel",
+		readArgs:  []string{"-ob"},
+		writeArgs: []string{"-ib"},
+		typeFlag:  "",
+	},
 }
 
-// GetName returns the platform name
-func (l *linuxPlatform) GetName() string {
-	return "Linux"
+// writeBin returns the binary used to write the clipboard, which for wl-clipboard
+// is wl-copy rather than the wl-paste binary used for reads.
+func (t unixTool) writeBin() string {
+	if t.bin == "wl-paste" {
+		return "wl-copy"
+	}
+	return t.bin
 }
 
-// GetContent retrieves text content from Linux clipboard
-func (l *linuxPlatform) GetContent() (string, error) {
-	// Try xclip first (supports both X11 and Wayland via XWayland)
-	content, err := l.getContentWithXclip()
-	if err == nil {
-		return content, nil
+// linuxPlatform implements Platform interface for Linux, delegating to whichever
+// unixTool was detected as available at startup.
+type linuxPlatform struct {
+	tool unixTool
+}
+
+// getPlatform returns the appropriate platform implementation for Linux, selecting
+// the first available clipboard tool and preferring Wayland-native tools when
+// running under a Wayland session. When none of wl-clipboard/xclip/xsel is on
+// PATH, it degrades to standardPlatform (golang.design/x/clipboard's in-process
+// X11/Wayland bindings) rather than failing construction, logging once so it's
+// clear a less capable backend is in use.
+func getPlatform() (Platform, error) {
+	tools := preferredUnixTools
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		// Not a Wayland session: don't prefer wl-clipboard over X11 tools.
+		tools = tools[1:]
 	}
 
-	// Fall back to xsel
-	content, err = l.getContentWithXsel()
-	if err != nil {
-		return "", fmt.Errorf("failed to get clipboard content (tried xclip and xsel): %w", err)
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool.bin); err == nil {
+			return &linuxPlatform{tool: tool}, nil
+		}
+	}
+
+	// Fall back to the full list (e.g. XWayland with wl-clipboard installed but no
+	// WAYLAND_DISPLAY set) before giving up.
+	for _, tool := range preferredUnixTools {
+		if _, err := exec.LookPath(tool.bin); err == nil {
+			return &linuxPlatform{tool: tool}, nil
+		}
 	}
 
-	return content, nil
+	log.Printf("clipboard: no supported tool found on PATH (tried wl-clipboard, xclip, xsel); falling back to the standard in-process backend")
+	return &standardPlatform{}, nil
 }
 
-// getContentWithXclip retrieves clipboard content using xclip
-func (l *linuxPlatform) getContentWithXclip() (string, error) {
-	cmd := exec.Command("xclip", "-selection", "clipboard", "-o")
+// GetName returns the platform name, including the clipboard tool in use so users
+// can tell which backend is active (e.g. "Linux/wl-clipboard").
+func (l *linuxPlatform) GetName() string {
+	return "Linux/" + l.tool.name
+}
+
+// GetContent retrieves text content from the Linux clipboard using the selected tool
+func (l *linuxPlatform) GetContent() (string, error) {
+	cmd := exec.Command(l.tool.bin, l.tool.readArgs...)
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("xclip failed: %w", err)
+		return "", fmt.Errorf("%s failed: %w", l.tool.name, err)
 	}
 
 	return strings.TrimSpace(string(output)), nil
 }
 
-// getContentWithXsel retrieves clipboard content using xsel
-func (l *linuxPlatform) getContentWithXsel() (string, error) {
-	cmd := exec.Command("xsel", "-ob")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("xsel failed: %w", err)
+// SetContent sets text content to the Linux clipboard using the selected tool
+func (l *linuxPlatform) SetContent(content string) error {
+	cmd := exec.Command(l.tool.writeBin(), l.tool.writeArgs...)
+	cmd.Stdin = strings.NewReader(content)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s set failed: %w", l.tool.name, err)
 	}
 
-	return strings.TrimSpace(string(output)), nil
+	return nil
 }
 
-// SetContent sets text content to Linux clipboard
-func (l *linuxPlatform) SetContent(content string) error {
-	// Try xclip first
-	err := l.setContentWithXclip(content)
-	if err == nil {
-		return nil
+// mimeToClipboardTarget maps a clipboard MIME type to the target name understood
+// by xclip/xsel/wl-paste's type-negotiation flags.
+func mimeToClipboardTarget(format string) string {
+	switch format {
+	case "text/plain", "":
+		return "UTF8_STRING"
+	case "text/html":
+		return "text/html"
+	case "image/png":
+		return "image/png"
+	case "text/uri-list":
+		return "text/uri-list"
+	default:
+		return format
+	}
+}
+
+// GetContentAs retrieves clipboard content for a specific MIME type via the selected
+// tool's type-negotiation flag, falling back to plain text retrieval for text/plain
+// if the tool doesn't support one (xsel has no arbitrary target flag).
+func (l *linuxPlatform) GetContentAs(format string) ([]byte, error) {
+	if l.tool.typeFlag == "" {
+		if format == "text/plain" || format == "" {
+			content, err := l.GetContent()
+			if err != nil {
+				return nil, err
+			}
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("%s does not support format negotiation, requested %s", l.tool.name, format)
 	}
 
-	// Fall back to xsel
-	err = l.setContentWithXsel(content)
+	target := mimeToClipboardTarget(format)
+	args := append(append([]string{}, l.tool.readArgs...), l.tool.typeFlag, target)
+	output, err := exec.Command(l.tool.bin, args...).Output()
 	if err != nil {
-		return fmt.Errorf("failed to set clipboard content (tried xclip and xsel): %w", err)
+		return nil, fmt.Errorf("failed to get clipboard content as %s via %s: %w", format, l.tool.name, err)
+	}
+
+	return output, nil
+}
+
+// SetContentAs writes data to the clipboard tagged with the given MIME type
+func (l *linuxPlatform) SetContentAs(format string, data []byte) error {
+	if l.tool.typeFlag == "" {
+		return fmt.Errorf("%s does not support format negotiation, requested %s", l.tool.name, format)
+	}
+
+	target := mimeToClipboardTarget(format)
+	args := append(append([]string{}, l.tool.writeArgs...), l.tool.typeFlag, target)
+	cmd := exec.Command(l.tool.writeBin(), args...)
+	cmd.Stdin = strings.NewReader(string(data))
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to set clipboard content as %s via %s: %w", format, l.tool.name, err)
 	}
 
 	return nil
 }
 
-// setContentWithXclip sets clipboard content using xclip
-func (l *linuxPlatform) setContentWithXclip(content string) error {
-	cmd := exec.Command("xclip", "-selection", "clipboard", "-i")
-	cmd.Stdin = strings.NewReader(content)
+// AvailableFormats lists the clipboard targets currently advertised by the selection
+// owner. Only xclip's TARGETS pseudo-target is supported for now.
+func (l *linuxPlatform) AvailableFormats() ([]string, error) {
+	if l.tool.bin != "xclip" {
+		return nil, fmt.Errorf("listing available formats is only supported via xclip")
+	}
 
-	err := cmd.Run()
+	output, err := exec.Command("xclip", "-selection", "clipboard", "-t", "TARGETS", "-o").Output()
 	if err != nil {
-		return fmt.Errorf("xclip set failed: %w", err)
+		return nil, fmt.Errorf("failed to list clipboard targets: %w", err)
 	}
 
-	return nil
+	var formats []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			formats = append(formats, line)
+		}
+	}
+
+	return formats, nil
 }
 
-// setContentWithXsel sets clipboard content using xsel
-func (l *linuxPlatform) setContentWithXsel(content string) error {
-	cmd := exec.Command("xsel", "-ib")
-	cmd.Stdin = strings.NewReader(content)
+// GetContentTyped returns every target the selection owner advertises via xclip;
+// other tools can't enumerate targets, so they return a single text/plain entry.
+func (l *linuxPlatform) GetContentTyped() ([]models.ClipboardContent, error) {
+	if l.tool.bin != "xclip" {
+		content, err := l.GetContent()
+		if err != nil {
+			return nil, err
+		}
+		return []models.ClipboardContent{{Format: "text/plain", Content: content, Raw: []byte(content)}}, nil
+	}
+	return contentTypedFromFormats(l)
+}
 
-	err := cmd.Run()
+// NotifyChanges subscribes to native clipboard-change events. Under Wayland it runs
+// `wl-paste --watch` (which re-invokes its command on every selection change) as a
+// long-lived subprocess; under X11, where no lightweight native listener is wired up
+// yet, it returns an error so the caller falls back to polling.
+func (l *linuxPlatform) NotifyChanges(ctx context.Context) (<-chan struct{}, error) {
+	if l.tool.bin != "wl-paste" {
+		return nil, fmt.Errorf("native clipboard change notifications require wl-clipboard")
+	}
+
+	cmd := exec.CommandContext(ctx, "wl-paste", "--watch", "echo", "changed")
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("xsel set failed: %w", err)
+		return nil, fmt.Errorf("failed to open wl-paste --watch stdout: %w", err)
 	}
 
-	return nil
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start wl-paste --watch: %w", err)
+	}
+
+	changes := make(chan struct{}, eventsChanBufferSize)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+		cmd.Wait() //nolint:errcheck // process exit is expected on ctx cancellation
+		close(changes)
+	}()
+
+	return changes, nil
 }
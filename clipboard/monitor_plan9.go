@@ -0,0 +1,9 @@
+//go:build plan9
+// +build plan9
+
+package clipboard
+
+// getPlatform returns the appropriate platform implementation for Plan 9
+func getPlatform() (Platform, error) {
+	return newPlan9Platform(""), nil
+}
@@ -5,7 +5,7 @@ import (
 	"testing"
 	"time"
 
-	"com.github/rethunk-tech/no-reaction/internal/models"
+	"com.github/rethunk-tech/tracetrim/internal/models"
 )
 
 // mockPlatform implements Platform interface for testing
@@ -15,6 +15,11 @@ type mockPlatform struct {
 	name          string
 	callCount     int
 	changeContent bool // Whether to change content on each call
+
+	// extraFormat and extraContent let tests exercise a second, non-text
+	// representation (e.g. "image/png") alongside the default text/plain one.
+	extraFormat  string
+	extraContent []byte
 }
 
 func (m *mockPlatform) GetContent() (string, error) {
@@ -38,6 +43,33 @@ func (m *mockPlatform) GetName() string {
 	return m.name
 }
 
+func (m *mockPlatform) GetContentAs(format string) ([]byte, error) {
+	if m.extraFormat != "" && format == m.extraFormat {
+		return m.extraContent, nil
+	}
+	content, err := m.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (m *mockPlatform) SetContentAs(format string, data []byte) error {
+	return m.SetContent(string(data))
+}
+
+func (m *mockPlatform) AvailableFormats() ([]string, error) {
+	formats := []string{"text/plain"}
+	if m.extraFormat != "" {
+		formats = append(formats, m.extraFormat)
+	}
+	return formats, nil
+}
+
+func (m *mockPlatform) GetContentTyped() ([]models.ClipboardContent, error) {
+	return contentTypedFromFormats(m)
+}
+
 func TestNewMonitor(t *testing.T) {
 	monitor, err := NewMonitor()
 	if err != nil {
@@ -243,21 +275,70 @@ func TestMonitor_PlatformInterface(t *testing.T) {
 	}
 }
 
-// Test platform-specific implementations
-func TestWindowsPlatform(t *testing.T) {
-	// This test would normally be skipped on non-Windows systems
-	// but we can at least verify the constants and structure
-	if cfUnicodeText != 13 {
-		t.Errorf("cfUnicodeText constant = %d, want 13", cfUnicodeText)
+// Note: Platform-specific types (linuxPlatform, darwinPlatform) are only available
+// when building on their respective platforms due to build tags.
+// These tests would be run on the appropriate platform during CI.
+
+func TestMonitor_AcceptedFormatsDefaultsToTextPlain(t *testing.T) {
+	platform := &mockPlatform{content: "initial", extraFormat: "image/png", extraContent: []byte{0x89, 0x50, 0x4e, 0x47}}
+	monitor := &Monitor{
+		platform: platform,
+		stopChan: make(chan struct{}),
+		events:   make(chan models.ClipboardContent, eventsChanBufferSize),
 	}
-	if gmemMoveable != 0x0002 {
-		t.Errorf("gmemMoveable constant = %d, want 0x0002", gmemMoveable)
+
+	var seen []models.ClipboardContent
+	callback := func(content models.ClipboardContent, m *Monitor) {
+		seen = append(seen, content)
+	}
+
+	monitor.pollOnce(callback)
+
+	for _, content := range seen {
+		if content.Format != "text/plain" {
+			t.Errorf("expected only text/plain to be dispatched by default, got format %q", content.Format)
+		}
 	}
 }
 
-// Note: Platform-specific types (linuxPlatform, darwinPlatform) are only available
-// when building on their respective platforms due to build tags.
-// These tests would be run on the appropriate platform during CI.
+func TestMonitor_AcceptedFormatsDispatchesOptedInFormats(t *testing.T) {
+	platform := &mockPlatform{content: "initial", extraFormat: "image/png", extraContent: []byte{0x89, 0x50, 0x4e, 0x47}}
+	monitor := &Monitor{
+		platform: platform,
+		stopChan: make(chan struct{}),
+		events:   make(chan models.ClipboardContent, eventsChanBufferSize),
+	}
+	monitor.SetAcceptedFormats([]string{"text/plain", "image/png"})
+
+	var seen []models.ClipboardContent
+	callback := func(content models.ClipboardContent, m *Monitor) {
+		seen = append(seen, content)
+	}
+
+	monitor.pollOnce(callback)
+
+	var sawImage bool
+	for _, content := range seen {
+		if content.Format == "image/png" {
+			sawImage = true
+			if string(content.Raw) != string(platform.extraContent) {
+				t.Errorf("image/png Raw = %v, want %v", content.Raw, platform.extraContent)
+			}
+		}
+	}
+	if !sawImage {
+		t.Error("expected image/png to be dispatched once opted into via SetAcceptedFormats")
+	}
+
+	// A second poll with unchanged content shouldn't redispatch the same image.
+	seen = nil
+	monitor.pollOnce(callback)
+	for _, content := range seen {
+		if content.Format == "image/png" {
+			t.Error("expected unchanged image/png content not to be redispatched")
+		}
+	}
+}
 
 // Integration test with actual platform (be careful with this)
 func TestMonitor_Integration(t *testing.T) {
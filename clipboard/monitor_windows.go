@@ -4,9 +4,13 @@
 package clipboard
 
 import (
+	"context"
 	"fmt"
+	"runtime"
 	"syscall"
 	"unsafe"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
 )
 
 // windowsPlatform implements Platform interface for Windows
@@ -151,20 +155,308 @@ var (
 	user32   = syscall.NewLazyDLL("user32.dll")
 	kernel32 = syscall.NewLazyDLL("kernel32.dll")
 
-	openClipboard    = user32.NewProc("OpenClipboard")
-	closeClipboard   = user32.NewProc("CloseClipboard")
-	getClipboardData = user32.NewProc("GetClipboardData")
-	setClipboardData = user32.NewProc("SetClipboardData")
-	emptyClipboard   = user32.NewProc("EmptyClipboard")
-	globalAlloc      = kernel32.NewProc("GlobalAlloc")
-	globalFree       = kernel32.NewProc("GlobalFree")
-	globalLock       = kernel32.NewProc("GlobalLock")
-	globalUnlock     = kernel32.NewProc("GlobalUnlock")
-	globalSize       = kernel32.NewProc("GlobalSize")
+	openClipboard           = user32.NewProc("OpenClipboard")
+	closeClipboard          = user32.NewProc("CloseClipboard")
+	getClipboardData        = user32.NewProc("GetClipboardData")
+	setClipboardData        = user32.NewProc("SetClipboardData")
+	emptyClipboard          = user32.NewProc("EmptyClipboard")
+	registerClipboardFormat = user32.NewProc("RegisterClipboardFormatW")
+	enumClipboardFormats    = user32.NewProc("EnumClipboardFormats")
+	getClipboardFormatName  = user32.NewProc("GetClipboardFormatNameW")
+	globalAlloc             = kernel32.NewProc("GlobalAlloc")
+	globalFree              = kernel32.NewProc("GlobalFree")
+	globalLock              = kernel32.NewProc("GlobalLock")
+	globalUnlock            = kernel32.NewProc("GlobalUnlock")
+	globalSize              = kernel32.NewProc("GlobalSize")
 )
 
 const (
 	cfUnicodeText = 13 // CF_UNICODETEXT
+	cfHDrop       = 15 // CF_HDROP
 	gmemMoveable  = 0x0002
 	gmemZeroInit  = 0x0040
+
+	// maxFormatNameLength bounds the buffer used by GetClipboardFormatNameW
+	maxFormatNameLength = 256
+)
+
+// registeredFormatName maps a MIME type to the name registered with RegisterClipboardFormatW
+func registeredFormatName(format string) (string, bool) {
+	switch format {
+	case "text/html":
+		return "HTML Format", true
+	case "image/png":
+		return "PNG", true
+	default:
+		return "", false
+	}
+}
+
+// clipboardFormatID resolves a MIME type to a Windows clipboard format ID, registering
+// custom formats on demand via RegisterClipboardFormatW.
+func clipboardFormatID(format string) (uintptr, error) {
+	switch format {
+	case "text/plain", "":
+		return cfUnicodeText, nil
+	case "text/uri-list":
+		return cfHDrop, nil
+	}
+
+	name, ok := registeredFormatName(format)
+	if !ok {
+		return 0, fmt.Errorf("unsupported clipboard format: %s", format)
+	}
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert format name to UTF-16: %w", err)
+	}
+
+	id, _, _ := registerClipboardFormat.Call(uintptr(unsafe.Pointer(namePtr)))
+	if id == 0 {
+		return 0, fmt.Errorf("failed to register clipboard format %q", name)
+	}
+
+	return id, nil
+}
+
+// getGlobalBytes reads the raw bytes backing a clipboard global memory handle
+func getGlobalBytes(formatID uintptr) ([]byte, error) {
+	hMem, _, _ := getClipboardData.Call(formatID)
+	if hMem == 0 {
+		return nil, fmt.Errorf("no data available in Windows clipboard (format: %d)", formatID)
+	}
+
+	lockRet, _, _ := globalLock.Call(hMem)
+	if lockRet == 0 {
+		return nil, fmt.Errorf("failed to lock Windows clipboard memory object")
+	}
+	defer globalUnlock.Call(hMem) //nolint:errcheck // Ignore errors in defer
+
+	size, _, _ := globalSize.Call(hMem)
+	if size == 0 {
+		return nil, fmt.Errorf("failed to get clipboard data size")
+	}
+
+	buf := make([]byte, size)
+	srcPtr := uintptr(lockRet)
+	dstPtr := uintptr(unsafe.Pointer(&buf[0]))
+	kernel32.NewProc("RtlMoveMemory").Call(dstPtr, srcPtr, size)
+
+	return buf, nil
+}
+
+// setGlobalBytes writes raw bytes to the clipboard tagged with the given format ID
+func setGlobalBytes(formatID uintptr, data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("refusing to set clipboard format %d with empty data", formatID)
+	}
+
+	hMem, _, _ := globalAlloc.Call(gmemMoveable|gmemZeroInit, uintptr(len(data)))
+	if hMem == 0 {
+		return fmt.Errorf("failed to allocate Windows global memory: insufficient memory")
+	}
+
+	lockRet, _, _ := globalLock.Call(hMem)
+	if lockRet == 0 {
+		globalFree.Call(hMem) //nolint:errcheck // Ignore errors in cleanup
+		return fmt.Errorf("failed to lock Windows clipboard memory object")
+	}
+
+	srcPtr := uintptr(unsafe.Pointer(&data[0]))
+	dstPtr := uintptr(lockRet)
+	kernel32.NewProc("RtlMoveMemory").Call(dstPtr, srcPtr, uintptr(len(data)))
+
+	unlockRet, _, _ := globalUnlock.Call(hMem)
+	if unlockRet == 0 {
+		globalFree.Call(hMem) //nolint:errcheck // Ignore errors in cleanup
+		return fmt.Errorf("failed to unlock Windows clipboard memory object")
+	}
+
+	if ret, _, _ := setClipboardData.Call(formatID, hMem); ret == 0 {
+		globalFree.Call(hMem) //nolint:errcheck // Ignore errors in cleanup
+		return fmt.Errorf("failed to set clipboard data for format %d", formatID)
+	}
+
+	return nil
+}
+
+// msg mirrors the Win32 MSG struct used by the GetMessage loop below
+type msg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+}
+
+const (
+	wmClipboardUpdate  = 0x031D
+	wmDestroy          = 0x0002
+	wsExNoActivate     = 0x08000000
+	hwndMessageOnlyPtr = ^uintptr(2) // HWND_MESSAGE (-3)
 )
+
+// NotifyChanges subscribes to WM_CLIPBOARDUPDATE via AddClipboardFormatListener on a
+// hidden message-only window, running a GetMessage loop on a dedicated OS thread
+// (required because the window and its message queue are thread-affine).
+func (w *windowsPlatform) NotifyChanges(ctx context.Context) (<-chan struct{}, error) {
+	createWindowEx := user32.NewProc("CreateWindowExW")
+	defWindowProc := user32.NewProc("DefWindowProcW")
+	getMessage := user32.NewProc("GetMessageW")
+	addClipboardFormatListener := user32.NewProc("AddClipboardFormatListener")
+	destroyWindow := user32.NewProc("DestroyWindow")
+	postQuitMessage := user32.NewProc("PostQuitMessage")
+
+	classNamePtr, err := syscall.UTF16PtrFromString("TraceTrimClipboardListener")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build window class name: %w", err)
+	}
+
+	changes := make(chan struct{}, eventsChanBufferSize)
+	ready := make(chan error, 1)
+
+	go func() {
+		// CreateWindowExW and GetMessageW must run on the same OS thread.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		hwnd, _, _ := createWindowEx.Call(
+			wsExNoActivate,
+			uintptr(unsafe.Pointer(classNamePtr)),
+			uintptr(unsafe.Pointer(classNamePtr)),
+			0, 0, 0, 0, 0,
+			hwndMessageOnlyPtr, 0, 0, 0,
+		)
+		if hwnd == 0 {
+			ready <- fmt.Errorf("failed to create message-only window for clipboard listener")
+			return
+		}
+
+		if ret, _, _ := addClipboardFormatListener.Call(hwnd); ret == 0 {
+			destroyWindow.Call(hwnd) //nolint:errcheck // best-effort cleanup
+			ready <- fmt.Errorf("AddClipboardFormatListener failed")
+			return
+		}
+
+		ready <- nil
+
+		go func() {
+			<-ctx.Done()
+			postQuitMessage.Call(0)
+		}()
+
+		var m msg
+		for {
+			ret, _, _ := getMessage.Call(uintptr(unsafe.Pointer(&m)), hwnd, 0, 0)
+			if ret == 0 || int32(ret) == -1 {
+				break
+			}
+			switch m.message {
+			case wmClipboardUpdate:
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			case wmDestroy:
+				break
+			default:
+				defWindowProc.Call(m.hwnd, uintptr(m.message), m.wParam, m.lParam) //nolint:errcheck
+			}
+		}
+
+		destroyWindow.Call(hwnd) //nolint:errcheck // best-effort cleanup
+		close(changes)
+	}()
+
+	if err := <-ready; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// GetContentAs retrieves clipboard content for a given MIME type, registering custom
+// formats (HTML, PNG) via RegisterClipboardFormatW as needed.
+func (w *windowsPlatform) GetContentAs(format string) ([]byte, error) {
+	formatID, err := clipboardFormatID(format)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _, _ := openClipboard.Call(0)
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to open Windows clipboard: access denied or clipboard in use")
+	}
+	defer closeClipboard.Call() //nolint:errcheck // Ignore errors in defer
+
+	return getGlobalBytes(formatID)
+}
+
+// SetContentAs writes data to the clipboard tagged with the given MIME type
+func (w *windowsPlatform) SetContentAs(format string, data []byte) error {
+	formatID, err := clipboardFormatID(format)
+	if err != nil {
+		return err
+	}
+
+	ret, _, _ := openClipboard.Call(0)
+	if ret == 0 {
+		return fmt.Errorf("failed to open Windows clipboard: access denied or clipboard in use")
+	}
+	defer closeClipboard.Call() //nolint:errcheck // Ignore errors in defer
+
+	if ret, _, _ := emptyClipboard.Call(); ret == 0 {
+		return fmt.Errorf("failed to empty Windows clipboard")
+	}
+
+	return setGlobalBytes(formatID, data)
+}
+
+// AvailableFormats enumerates the clipboard formats currently on offer via EnumClipboardFormats
+func (w *windowsPlatform) AvailableFormats() ([]string, error) {
+	ret, _, _ := openClipboard.Call(0)
+	if ret == 0 {
+		return nil, fmt.Errorf("failed to open Windows clipboard: access denied or clipboard in use")
+	}
+	defer closeClipboard.Call() //nolint:errcheck // Ignore errors in defer
+
+	var formats []string
+	var formatID uintptr
+	for {
+		next, _, _ := enumClipboardFormats.Call(formatID)
+		if next == 0 {
+			break
+		}
+		formatID = next
+
+		switch formatID {
+		case cfUnicodeText:
+			formats = append(formats, "text/plain")
+		case cfHDrop:
+			formats = append(formats, "text/uri-list")
+		default:
+			nameBuf := make([]uint16, maxFormatNameLength)
+			n, _, _ := getClipboardFormatName.Call(formatID, uintptr(unsafe.Pointer(&nameBuf[0])), maxFormatNameLength)
+			if n > 0 {
+				name := syscall.UTF16ToString(nameBuf[:n])
+				// Report registered formats under the MIME type GetContentAs accepts.
+				switch name {
+				case "HTML Format":
+					name = "text/html"
+				case "PNG":
+					name = "image/png"
+				}
+				formats = append(formats, name)
+			}
+		}
+	}
+
+	return formats, nil
+}
+
+// GetContentTyped returns every format EnumClipboardFormats currently reports
+func (w *windowsPlatform) GetContentTyped() ([]models.ClipboardContent, error) {
+	return contentTypedFromFormats(w)
+}
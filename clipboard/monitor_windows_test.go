@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import "testing"
+
+// TestWindowsPlatform verifies the Win32 clipboard format constants, which
+// only exist in monitor_windows.go and so can only be tested on Windows.
+func TestWindowsPlatform(t *testing.T) {
+	if cfUnicodeText != 13 {
+		t.Errorf("cfUnicodeText constant = %d, want 13", cfUnicodeText)
+	}
+	if gmemMoveable != 0x0002 {
+		t.Errorf("gmemMoveable constant = %d, want 0x0002", gmemMoveable)
+	}
+}
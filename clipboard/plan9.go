@@ -0,0 +1,97 @@
+package clipboard
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
+)
+
+// defaultSnarfPath is the well-known location of Plan 9's clipboard device
+const defaultSnarfPath = "/dev/snarf"
+
+// plan9Platform implements Platform by reading and writing Plan 9's /dev/snarf
+// clipboard device directly. The device path is injectable so tests can point it
+// at a temp file standing in for /dev/snarf on hosts that don't have one.
+type plan9Platform struct {
+	devicePath string
+}
+
+// newPlan9Platform creates a plan9Platform, defaulting to /dev/snarf when no
+// device path is given.
+func newPlan9Platform(devicePath string) *plan9Platform {
+	if devicePath == "" {
+		devicePath = defaultSnarfPath
+	}
+	return &plan9Platform{devicePath: devicePath}
+}
+
+// GetName returns the platform name
+func (p *plan9Platform) GetName() string {
+	return "Plan9"
+}
+
+// GetContent reads the entire contents of the snarf buffer
+func (p *plan9Platform) GetContent() (string, error) {
+	f, err := os.Open(p.devicePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", p.devicePath, err)
+	}
+	defer f.Close() //nolint:errcheck // read-only handle, nothing to flush
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", p.devicePath, err)
+	}
+
+	return string(data), nil
+}
+
+// SetContent replaces the snarf buffer with content
+func (p *plan9Platform) SetContent(content string) error {
+	f, err := os.OpenFile(p.devicePath, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s for writing: %w", p.devicePath, err)
+	}
+	defer f.Close() //nolint:errcheck // best-effort close after a successful write
+
+	if _, err := f.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", p.devicePath, err)
+	}
+
+	return nil
+}
+
+// GetContentAs retrieves clipboard content; the snarf buffer only ever holds text
+func (p *plan9Platform) GetContentAs(format string) ([]byte, error) {
+	if format != "" && format != "text/plain" {
+		return nil, fmt.Errorf("plan9 snarf buffer only supports text/plain, got %s", format)
+	}
+
+	content, err := p.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(content), nil
+}
+
+// SetContentAs writes content to the snarf buffer; only text/plain is supported
+func (p *plan9Platform) SetContentAs(format string, data []byte) error {
+	if format != "" && format != "text/plain" {
+		return fmt.Errorf("plan9 snarf buffer only supports text/plain, got %s", format)
+	}
+
+	return p.SetContent(string(data))
+}
+
+// AvailableFormats reports the formats the snarf buffer can hold
+func (p *plan9Platform) AvailableFormats() ([]string, error) {
+	return []string{"text/plain"}, nil
+}
+
+// GetContentTyped returns the snarf buffer's single text/plain representation
+func (p *plan9Platform) GetContentTyped() ([]models.ClipboardContent, error) {
+	return contentTypedFromFormats(p)
+}
@@ -0,0 +1,60 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlan9Platform_GetSetContent(t *testing.T) {
+	snarf := filepath.Join(t.TempDir(), "snarf")
+	if err := os.WriteFile(snarf, []byte("initial"), 0o600); err != nil {
+		t.Fatalf("failed to seed snarf file: %v", err)
+	}
+
+	platform := newPlan9Platform(snarf)
+
+	content, err := platform.GetContent()
+	if err != nil {
+		t.Fatalf("GetContent() failed: %v", err)
+	}
+	if content != "initial" {
+		t.Errorf("GetContent() = %q, want %q", content, "initial")
+	}
+
+	if err := platform.SetContent("updated snarf buffer"); err != nil {
+		t.Fatalf("SetContent() failed: %v", err)
+	}
+
+	content, err = platform.GetContent()
+	if err != nil {
+		t.Fatalf("GetContent() after SetContent failed: %v", err)
+	}
+	if content != "updated snarf buffer" {
+		t.Errorf("GetContent() after SetContent = %q, want %q", content, "updated snarf buffer")
+	}
+}
+
+func TestPlan9Platform_GetName(t *testing.T) {
+	platform := newPlan9Platform("")
+	if platform.GetName() != "Plan9" {
+		t.Errorf("GetName() = %q, want %q", platform.GetName(), "Plan9")
+	}
+}
+
+func TestPlan9Platform_DefaultsToSnarfDevice(t *testing.T) {
+	if defaultSnarfPath != "/dev/snarf" {
+		t.Errorf("defaultSnarfPath = %q, want %q", defaultSnarfPath, "/dev/snarf")
+	}
+	if platform := newPlan9Platform(""); platform.devicePath != defaultSnarfPath {
+		t.Errorf("newPlan9Platform(\"\").devicePath = %q, want %q", platform.devicePath, defaultSnarfPath)
+	}
+}
+
+func TestPlan9Platform_GetContentAsRejectsOtherFormats(t *testing.T) {
+	platform := newPlan9Platform(filepath.Join(t.TempDir(), "snarf"))
+
+	if _, err := platform.GetContentAs("image/png"); err == nil {
+		t.Error("GetContentAs(\"image/png\") should fail, snarf buffer is text-only")
+	}
+}
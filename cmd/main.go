@@ -3,19 +3,33 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"com.github/rethunk-tech/tracetrim/clipboard"
+	"com.github/rethunk-tech/tracetrim/internal/archive"
 	"com.github/rethunk-tech/tracetrim/internal/config"
+	"com.github/rethunk-tech/tracetrim/internal/history"
+	tracelog "com.github/rethunk-tech/tracetrim/internal/log"
+	"com.github/rethunk-tech/tracetrim/internal/markdown"
+	"com.github/rethunk-tech/tracetrim/internal/metrics"
 	"com.github/rethunk-tech/tracetrim/internal/models"
+	"com.github/rethunk-tech/tracetrim/internal/parser/codeframe"
+	"com.github/rethunk-tech/tracetrim/internal/report"
+	"com.github/rethunk-tech/tracetrim/internal/sourcemap"
 	"com.github/rethunk-tech/tracetrim/parser"
 	"github.com/mattn/go-isatty"
+	"github.com/spf13/pflag"
 )
 
 // version is set during build time via ldflags
@@ -25,6 +39,11 @@ var version = "dev"
 const (
 	stackTraceTypeReact      = "React"
 	stackTraceTypeJavaScript = "JavaScript"
+	stackTraceTypeGo         = "Go"
+	stackTraceTypePython     = "Python"
+	stackTraceTypeJava       = "Java"
+	stackTraceTypeDotNet     = ".NET"
+	stackTraceTypeRuby       = "Ruby"
 )
 
 // isNonInteractiveEnvironment detects if we're running in a non-interactive environment
@@ -48,7 +67,70 @@ func isNonInteractiveEnvironment() bool {
 	return false
 }
 
+// deps bundles the runtime collaborators handleClipboardContent and
+// processStackTrace need, so adding a new one (metrics, archiving, logging)
+// doesn't keep growing their parameter lists.
+type deps struct {
+	collector *metrics.Collector
+	archiver  *archive.Writer
+	history   *history.Writer
+	logger    *tracelog.Logger
+}
+
+// newLogger builds the structured logger used for monitoring-mode output,
+// honoring Output.LogFormat/LogFile/Verbose/Quiet/ShowTimestamp. The returned
+// closer must be called before the process exits if LogFile was set.
+func newLogger(cfg *config.Config) (*tracelog.Logger, func(), error) {
+	out := io.Writer(os.Stdout)
+	closer := func() {}
+
+	if cfg.Output.LogFile != "" {
+		f, err := os.OpenFile(cfg.Output.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file %s: %w", cfg.Output.LogFile, err)
+		}
+		out = f
+		closer = func() { f.Close() } //nolint:errcheck // best-effort close on shutdown
+	}
+
+	minLevel := tracelog.LevelInfo
+	switch {
+	case cfg.Output.Quiet:
+		minLevel = tracelog.LevelWarn
+	case cfg.Output.Verbose:
+		minLevel = tracelog.LevelDebug
+	}
+
+	format := tracelog.FormatText
+	if cfg.Output.LogFormat == config.LogFormatJSON {
+		format = tracelog.FormatJSON
+	}
+
+	logger := tracelog.New(tracelog.Config{
+		Out:           out,
+		Format:        format,
+		MinLevel:      minLevel,
+		ShowTimestamp: cfg.Output.ShowTimestamp,
+		Tracing:       cfg.Output.Verbose,
+	})
+
+	return logger, closer, nil
+}
+
 func main() {
+	// Dispatch history subcommands before binding the monitoring-mode flags,
+	// since they take their own flag sets (e.g. "replay --since=1h").
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			runReplayCommand(os.Args[2:])
+			return
+		case "history":
+			runHistoryCommand(os.Args[2:])
+			return
+		}
+	}
+
 	// Bind command line flags to viper
 	if err := config.BindFlags(); err != nil {
 		log.Fatalf("Failed to bind flags: %v", err)
@@ -65,6 +147,25 @@ func main() {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
 
+	// Exclude any disabled dialects from automatic detection
+	parser.SetDisabledDialects(cfg.Parser.DisabledDialects)
+
+	// Install any user-defined stack trace patterns (already validated by
+	// ValidateConfig, but compiled afresh here since parser doesn't import
+	// config)
+	if err := parser.SetCustomPatterns(cfg.Parser.CustomPatternSpecs, cfg.Parser.MinStackLinesForDetection); err != nil {
+		log.Fatalf("Invalid custom patterns: %v", err)
+	}
+
+	// Resolve JS/TS frames through their source maps if enabled
+	if cfg.Parser.SourceMapEnabled {
+		parser.SetSourceMapResolver(sourcemap.NewResolver(cfg.Parser.SourceMapSearchRoot, cfg.Parser.SourceMapAllowNetworkFetch))
+	}
+
+	// Render a code frame around the top frame's error position
+	parser.SetCodeFrameContextLines(cfg.Parser.CodeFrame.ContextLines)
+	codeframe.EnableColor = !cfg.Parser.CodeFrame.NoColor
+
 	// Auto-detect script mode if in non-interactive environment and auto-detection is enabled
 	if !cfg.ScriptMode && cfg.AutoDetectScriptMode && isNonInteractiveEnvironment() {
 		cfg.ScriptMode = true
@@ -73,11 +174,14 @@ func main() {
 		}
 	}
 
-	// Set up logging based on configuration
-	// Note: File logging is not implemented in this version
-
 	// Check if script mode is enabled (either manually or auto-detected)
 	if cfg.ScriptMode {
+		if cfg.Script.Stream {
+			// Streaming NDJSON protocol: keep the process alive and serve
+			// one request per STDIN line until EOF or a shutdown signal
+			runStreamMode(cfg)
+			return
+		}
 		// Run in script mode - read from STDIN, process, write to STDOUT, then exit
 		// No header output in script mode to avoid breaking scripts
 		runScriptMode(cfg)
@@ -95,21 +199,87 @@ func main() {
 		fmt.Println("Press Ctrl+C to exit")
 	}
 
+	// Set up signal handling for graceful shutdown; metrics serving/pushing and
+	// clipboard monitoring all share this context's lifetime.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Set up the structured logger used for all monitoring-mode diagnostics
+	appLogger, closeLogger, err := newLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer closeLogger()
+
+	// Set up metrics collection, serving and/or pushing per configuration
+	collector := metrics.NewCollector()
+
+	if cfg.Metrics.Enabled {
+		go func() {
+			if err := collector.Serve(ctx, cfg.Metrics.ListenAddr, cfg.Metrics.Path); err != nil {
+				appLogger.Error("metrics server stopped", tracelog.F("error", err.Error()))
+			}
+		}()
+	}
+	if cfg.Metrics.PushEnabled {
+		go collector.StartPushing(ctx, metrics.PushConfig{
+			URL:      cfg.Metrics.PushURL,
+			Interval: cfg.Metrics.PushInterval,
+			Job:      "tracetrim",
+		})
+	}
+
+	// Set up the cleaned-trace archive writer if archiving is enabled
+	var archiver *archive.Writer
+	if cfg.Output.ArchivePath != "" {
+		var archiveErr error
+		archiver, archiveErr = archive.NewWriter(cfg.Output.ArchivePath, cfg.Output.ArchiveMaxSize, cfg.Output.ArchiveRetention)
+		if archiveErr != nil {
+			log.Fatalf("Failed to initialize archive: %v", archiveErr)
+		}
+	}
+
+	// Set up the replayable history log writer if history recording is enabled
+	var historyWriter *history.Writer
+	if cfg.History.Dir != "" {
+		var historyErr error
+		historyWriter, historyErr = history.NewWriter(cfg.History.Dir, cfg.History.MaxEntries)
+		if historyErr != nil {
+			log.Fatalf("Failed to initialize history: %v", historyErr)
+		}
+	}
+
 	// Create clipboard monitor
 	monitor, err := clipboard.NewMonitor()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to initialize clipboard monitor: %v\n", err)
-		fmt.Fprintf(os.Stderr, "This may be due to:\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions to access clipboard\n")
-		fmt.Fprintf(os.Stderr, "  - Platform-specific requirements not met\n")
-		fmt.Fprintf(os.Stderr, "  - Missing system dependencies\n")
-		fmt.Fprintf(os.Stderr, "\nPlease check the troubleshooting section in the README.\n")
+		appLogger.Error("failed to initialize clipboard monitor", tracelog.F("error", err.Error()),
+			tracelog.F("hint", "check clipboard permissions, platform requirements, and system dependencies; see the README troubleshooting section"))
 		os.Exit(1)
 	}
+	monitor.SetAcceptedFormats(cfg.Clipboard.AcceptedFormats)
 
-	// Set up signal handling for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Apply config hot reloads: recreate the monitor's ticker when the poll
+	// interval changes, refresh its accepted formats, and update cfg in place
+	// so every function already holding this *config.Config pointer (they all
+	// take one) sees the new values without a restart.
+	config.EnableHotReload()
+	config.Subscribe(func(old, newCfg *config.Config) {
+		if newCfg.Clipboard.PollingInterval != old.Clipboard.PollingInterval {
+			monitor.SetPollInterval(newCfg.Clipboard.PollingInterval)
+		}
+		if !slices.Equal(newCfg.Clipboard.AcceptedFormats, old.Clipboard.AcceptedFormats) {
+			monitor.SetAcceptedFormats(newCfg.Clipboard.AcceptedFormats)
+		}
+		if newCfg.Parser.CustomPatternSpecs != old.Parser.CustomPatternSpecs ||
+			newCfg.Parser.MinStackLinesForDetection != old.Parser.MinStackLinesForDetection {
+			if err := parser.SetCustomPatterns(newCfg.Parser.CustomPatternSpecs, newCfg.Parser.MinStackLinesForDetection); err != nil {
+				appLogger.Error("hot reload produced invalid custom patterns, keeping previous patterns", tracelog.F("error", err.Error()))
+			}
+		}
+		*cfg = *newCfg
+	})
+
+	runtimeDeps := deps{collector: collector, archiver: archiver, history: historyWriter, logger: appLogger}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -117,18 +287,18 @@ func main() {
 	// Start monitoring in a goroutine
 	go func() {
 		callback := func(content models.ClipboardContent, m *clipboard.Monitor) {
-			handleClipboardContent(content, m, cfg)
+			handleClipboardContent(content, m, cfg, runtimeDeps)
 		}
 		err := monitor.StartMonitoringWithInterval(ctx, cfg.Clipboard.PollingInterval, callback)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Clipboard monitoring stopped: %v\n", err)
+			appLogger.Error("clipboard monitoring stopped", tracelog.F("error", err.Error()))
 			// Try to restart monitoring after a delay
 			time.Sleep(5 * time.Second)
-			fmt.Fprintf(os.Stderr, "Info: Attempting to restart clipboard monitoring...\n")
+			appLogger.Info("attempting to restart clipboard monitoring")
 			go func() {
 				restartErr := monitor.StartMonitoringWithInterval(ctx, cfg.Clipboard.PollingInterval, callback)
 				if restartErr != nil {
-					fmt.Fprintf(os.Stderr, "Error: Failed to restart monitoring: %v\n", restartErr)
+					appLogger.Error("failed to restart clipboard monitoring", tracelog.F("error", restartErr.Error()))
 				}
 			}()
 		}
@@ -140,6 +310,67 @@ func main() {
 	monitor.Stop()
 }
 
+// runReplayCommand implements `tracetrim replay`, which re-emits traces
+// recorded in the history log. With --re-clean it reruns detection/cleaning
+// against each entry's original content instead of replaying the stored
+// result, which is useful after a dialect or dedup rule improves.
+func runReplayCommand(args []string) {
+	fs := pflag.NewFlagSet("replay", pflag.ExitOnError)
+	since := fs.Duration("since", 0, "Only replay entries recorded within this duration of now (0 replays the entire history)")
+	reClean := fs.Bool("re-clean", false, "Re-run detection/cleaning against each entry's original content instead of replaying the stored result")
+	historyDir := fs.String("history-dir", "", "Directory the history log is read from")
+	dialect := fs.String("dialect", config.DefaultDialect, "Stack trace dialect to re-clean with when --re-clean is set")
+	if err := fs.Parse(args); err != nil {
+		log.Fatalf("failed to parse replay flags: %v", err)
+	}
+
+	entries, err := history.Entries(*historyDir)
+	if err != nil {
+		log.Fatalf("failed to read history: %v", err)
+	}
+	if *since > 0 {
+		entries = history.Since(entries, *since)
+	}
+
+	for _, entry := range entries {
+		if !*reClean {
+			fmt.Println(entry.Cleaned)
+			continue
+		}
+
+		cleanResult, detected := cleanContent(entry.Original, *dialect)
+		if !detected {
+			fmt.Fprintf(os.Stderr, "replay: %s no longer detected as a stack trace, skipping\n", entry.Hash)
+			continue
+		}
+		fmt.Println(cleanResult.Cleaned)
+	}
+}
+
+// runHistoryCommand implements `tracetrim history <subcommand>`.
+func runHistoryCommand(args []string) {
+	if len(args) == 0 || args[0] != "stats" {
+		fmt.Fprintln(os.Stderr, "usage: tracetrim history stats [--history-dir=DIR]")
+		os.Exit(1)
+	}
+
+	fs := pflag.NewFlagSet("history stats", pflag.ExitOnError)
+	historyDir := fs.String("history-dir", "", "Directory the history log is read from")
+	if err := fs.Parse(args[1:]); err != nil {
+		log.Fatalf("failed to parse history flags: %v", err)
+	}
+
+	entries, err := history.Entries(*historyDir)
+	if err != nil {
+		log.Fatalf("failed to read history: %v", err)
+	}
+
+	stats := history.Aggregate(entries)
+	fmt.Printf("entries:        %d\n", stats.Count)
+	fmt.Printf("bytes saved:    %d\n", stats.TotalBytesSaved)
+	fmt.Printf("frames removed: %d\n", stats.TotalFramesRemoved)
+}
+
 // runScriptMode processes stack traces from STDIN and outputs results to STDOUT
 func runScriptMode(cfg *config.Config) {
 	// Read all input from STDIN
@@ -174,12 +405,6 @@ func runScriptMode(cfg *config.Config) {
 		return
 	}
 
-	// Process the content as a potential stack trace
-	processScriptInput(content, cfg)
-}
-
-// processScriptInput handles the core logic for script mode processing
-func processScriptInput(content string, cfg *config.Config) {
 	// Check content size limit
 	if len(content) > cfg.Clipboard.MaxContentSize {
 		if cfg.Output.Verbose {
@@ -191,43 +416,267 @@ func processScriptInput(content string, cfg *config.Config) {
 		return
 	}
 
-	// Check if this looks like a stack trace
-	if !parser.IsStackTrace(content) {
-		// In script mode, output non-stack-trace content verbatim for compatibility
-		fmt.Print(content)
+	// Process the content as a potential stack trace, or as a Markdown
+	// document with embedded stack traces in fenced code blocks
+	if cfg.Script.InputFormat == config.ScriptInputMarkdown {
+		processMarkdownInput(content, cfg)
+		return
+	}
+	processScriptInput(content, cfg)
+}
+
+// processScriptInput handles the core logic for script mode processing
+func processScriptInput(content string, cfg *config.Config) {
+	cleanResult, detected := cleanContent(content, cfg.Parser.Dialect)
+	if !detected {
 		if cfg.Output.Verbose {
 			fmt.Fprintf(os.Stderr, "No stack trace detected in input, passing through verbatim\n")
 		}
+		outputScriptResult(cfg.Script.OutputFormat, report.Result{Detected: false, Original: content, Cleaned: content})
 		return
 	}
 
-	// Process stack trace
-	cleanResult := parser.CleanResult(content)
+	outputScriptResult(cfg.Script.OutputFormat, scriptResult(&cleanResult))
+}
 
-	// Check if content actually changed
-	if cleanResult.Cleaned == content {
-		// Content is already clean, output it verbatim (no verbose message in script mode)
-		outputScriptResult(&cleanResult)
+// cleanContent detects and cleans content as a stack trace, dispatching
+// through the parser.Dialect registry. dialectName of config.DialectAuto
+// picks a dialect automatically (parser.IsStackTrace/CleanResult); any other
+// value forces that specific dialect, skipping detection entirely if it
+// doesn't recognize content. Returns false when no matching trace was found.
+func cleanContent(content string, dialectName string) (models.CleanResult, bool) {
+	if dialectName == config.DialectAuto {
+		if !parser.IsStackTrace(content) {
+			return models.CleanResult{}, false
+		}
+		return parser.CleanResult(content), true
+	}
+
+	dialect, ok := parser.LookupDialect(dialectName)
+	if !ok || !dialect.Detect(content) {
+		return models.CleanResult{}, false
+	}
+
+	return buildDialectCleanResult(content, dialect), true
+}
+
+// buildDialectCleanResult mirrors parser.CleanResult's bookkeeping for a
+// Clean call made against an explicitly forced dialect, since parser.CleanResult
+// itself always re-detects the dialect via DetectDialect.
+func buildDialectCleanResult(content string, dialect parser.Dialect) models.CleanResult {
+	pair := dialect.Clean(content)
+
+	return models.CleanResult{
+		Original:      content,
+		Cleaned:       pair.Content,
+		Removed:       pair.Removed,
+		BytesSaved:    len(content) - len(pair.Content),
+		LinesBefore:   strings.Count(content, "\n") + 1,
+		LinesAfter:    strings.Count(pair.Content, "\n") + 1,
+		Frames:        pair.Frames,
+		ErrorInfo:     parser.ExtractErrorInfo(content),
+		Language:      parser.LanguageForDialect(dialect.Name()),
+		Cycles:        parser.DetectCycles(parser.FrameSignatures(content)),
+		RemovedGroups: pair.RemovedGroups,
+	}
+}
+
+// scriptResult converts a parser.CleanResult into the format-agnostic shape
+// the report package encodes.
+func scriptResult(cleanResult *models.CleanResult) report.Result {
+	result := report.Result{
+		Detected:      true,
+		StackType:     stackTypeForResult(cleanResult),
+		Original:      cleanResult.Original,
+		Cleaned:       cleanResult.Cleaned,
+		FramesRemoved: cleanResult.Removed,
+		BytesSaved:    cleanResult.BytesSaved,
+	}
+
+	if cleanResult.ErrorInfo != nil {
+		result.ErrorInfo = &report.ErrorInfo{
+			Message:   cleanResult.ErrorInfo.Message,
+			Source:    cleanResult.ErrorInfo.Source,
+			Component: cleanResult.ErrorInfo.Component,
+			CodeFrame: cleanResult.ErrorInfo.CodeFrame,
+		}
+	}
+
+	for _, frame := range cleanResult.Frames {
+		result.Frames = append(result.Frames, report.Frame{
+			Function:       frame.Function,
+			File:           frame.File,
+			Line:           frame.Line,
+			Column:         frame.Column,
+			CollapsedCount: frame.CollapsedCount,
+			OriginalSource: frame.OriginalSource,
+			IsNative:       frame.IsNative,
+			Raw:            frame.Raw,
+		})
+	}
+
+	return result
+}
+
+// outputScriptResult renders result to STDOUT in the configured format. A
+// rendering error (only possible for an invalid format, since writes to
+// STDOUT don't fail in practice) is reported to STDERR rather than silently
+// dropped, since script mode has no other way to surface it.
+func outputScriptResult(format string, result report.Result) {
+	if err := report.Encode(os.Stdout, format, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render script mode output: %v\n", err)
+	}
+}
+
+// processMarkdownInput cleans only the fenced code blocks in a Markdown
+// document, leaving prose and non-matching blocks byte-for-byte untouched.
+// This lets users paste a whole bug report (a GitHub issue, a Slack export)
+// through TraceTrim instead of extracting the stack trace first.
+func processMarkdownInput(doc string, cfg *config.Config) {
+	rewritten, blocks := markdown.Transform(doc, func(content string) (string, bool) {
+		cleanResult, detected := cleanContent(content, cfg.Parser.Dialect)
+		if !detected {
+			return content, false
+		}
+		return cleanResult.Cleaned, cleanResult.Cleaned != content
+	})
+
+	fmt.Print(rewritten)
+
+	if !cfg.Output.Verbose {
 		return
 	}
+	for i, block := range blocks {
+		if !block.Changed {
+			fmt.Fprintf(os.Stderr, "block %d (%s): no stack trace detected, left untouched\n", i+1, block.Fence)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "block %d (%s): cleaned, %d bytes saved\n", i+1, block.Fence, len(block.Original)-len(block.Cleaned))
+	}
+}
 
-	// Output the cleaned result
-	outputScriptResult(&cleanResult)
+// streamRequest is one line of a --stream mode STDIN request: a single trace
+// to clean, tagged with a caller-chosen ID so out-of-order or pipelined
+// callers (editors, LSP plugins) can match it to its response.
+type streamRequest struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
 }
 
-// outputScriptResult formats and outputs the result based on script mode configuration
-func outputScriptResult(cleanResult *models.CleanResult) {
-	// In script mode, only output the cleaned content to STDOUT
-	// No statistics to STDERR to avoid breaking scripts
-	fmt.Print(cleanResult.Cleaned)
+// streamResponse is one line of a --stream mode STDOUT response.
+type streamResponse struct {
+	ID            string  `json:"id"`
+	Error         string  `json:"error,omitempty"`
+	StackType     string  `json:"stack_type,omitempty"`
+	Cleaned       string  `json:"cleaned"`
+	Detected      bool    `json:"detected"`
+	FramesRemoved int     `json:"frames_removed"`
+	BytesSaved    int     `json:"bytes_saved"`
+	PercentSaved  float64 `json:"percent_saved"`
 }
 
-// plural returns "s" if count != 1, otherwise returns empty string
-func plural(count int) string {
-	if count == 1 {
-		return ""
+// runStreamMode serves the streaming NDJSON protocol: one streamRequest per
+// STDIN line, one streamResponse per STDOUT line, until STDIN hits EOF or a
+// shutdown signal arrives. A single long-lived process can then serve many
+// traces instead of an editor or LSP plugin re-execing TraceTrim per paste.
+func runStreamMode(cfg *config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	streamLoop(ctx, cfg)
+}
+
+// streamLoop reads and responds to requests until STDIN is exhausted or ctx
+// is canceled. A cancellation while no request is in flight (the goroutine is
+// simply blocked waiting on the next STDIN line) returns immediately; one
+// that arrives mid-request gives that request up to
+// cfg.Script.StreamShutdownTimeout to finish before returning anyway.
+func streamLoop(ctx context.Context, cfg *config.Config) {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), cfg.Clipboard.MaxContentSize+64*1024)
+	encoder := json.NewEncoder(os.Stdout)
+
+	var requestActive atomic.Bool
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			requestActive.Store(true)
+			err := encoder.Encode(handleStreamLine(line, cfg))
+			requestActive.Store(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to encode stream response: %v\n", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+	}()
+
+	shutdownTimeout := cfg.Script.StreamShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = config.DefaultStreamShutdownTimeout
+	}
+
+	select {
+	case <-done:
+		// STDIN reached EOF; every queued request was drained normally.
+	case <-ctx.Done():
+		if !requestActive.Load() {
+			// Nothing was in flight - the goroutine is just blocked waiting
+			// on the next STDIN line, so there's nothing to wait for.
+			return
+		}
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+			fmt.Fprintln(os.Stderr, "Stream mode shutdown timed out waiting for in-flight request")
+		}
+	}
+}
+
+// handleStreamLine decodes one request envelope and cleans its content,
+// returning the response envelope to emit.
+func handleStreamLine(line string, cfg *config.Config) streamResponse {
+	var req streamRequest
+	if err := json.Unmarshal([]byte(line), &req); err != nil {
+		return streamResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+	}
+
+	if len(req.Content) > cfg.Clipboard.MaxContentSize {
+		return streamResponse{ID: req.ID, Error: fmt.Sprintf("content too large (%d bytes)", len(req.Content))}
+	}
+
+	cleanResult, detected := cleanContent(req.Content, cfg.Parser.Dialect)
+	if !detected {
+		return streamResponse{ID: req.ID, Cleaned: req.Content, Detected: false}
+	}
+
+	result := scriptResult(&cleanResult)
+
+	return streamResponse{
+		ID:            req.ID,
+		Cleaned:       result.Cleaned,
+		Detected:      true,
+		StackType:     result.StackType,
+		FramesRemoved: result.FramesRemoved,
+		BytesSaved:    result.BytesSaved,
+		PercentSaved:  result.PercentSaved(),
 	}
-	return "s"
 }
 
 // getStackTraceType determines the type of stack trace for better user feedback
@@ -263,138 +712,147 @@ func getStackTraceType(errorInfo *models.ErrorInfo, originalContent string) stri
 	return stackTraceTypeJavaScript
 }
 
+// stackTypeForResult returns a human-readable stack trace type label for a
+// cleaned result. It trusts the detected Language where the parser already
+// disambiguates it (Go, Python, Java) and falls back to content heuristics
+// to tell React apart from plain JavaScript, since both share the same
+// Language value.
+func stackTypeForResult(cleanResult *models.CleanResult) string {
+	switch cleanResult.Language {
+	case models.LanguageGo:
+		return stackTraceTypeGo
+	case models.LanguagePython:
+		return stackTraceTypePython
+	case models.LanguageJava:
+		return stackTraceTypeJava
+	case models.LanguageDotNet:
+		return stackTraceTypeDotNet
+	case models.LanguageRuby:
+		return stackTraceTypeRuby
+	default:
+		return getStackTraceType(cleanResult.ErrorInfo, cleanResult.Original)
+	}
+}
+
 // handleClipboardContent processes clipboard content when it changes
-func handleClipboardContent(content models.ClipboardContent, monitor *clipboard.Monitor, cfg *config.Config) {
+func handleClipboardContent(content models.ClipboardContent, monitor *clipboard.Monitor, cfg *config.Config, rt deps) {
+	rt.collector.Polls.Inc()
+
 	// Check content size limit
 	if len(content.Content) > cfg.Clipboard.MaxContentSize {
-		handleContentTooLarge(content, cfg)
+		rt.collector.OversizeRejections.Inc()
+		rt.logger.Warn("content too large, skipping", tracelog.F("bytes", len(content.Content)), tracelog.F("limit_bytes", cfg.Clipboard.MaxContentSize))
 		return
 	}
 
-	// Check if this looks like a stack trace
-	if !parser.IsStackTrace(content.Content) {
-		if cfg.Output.Verbose {
-			timestamp := GetTimestamp(content, cfg)
-			fmt.Printf("%sSkipping non-stack-trace content\n", timestamp)
-		}
+	if !utf8.ValidString(content.Content) {
+		rt.collector.InvalidUTF8Rejections.Inc()
+		rt.logger.Debug("skipping content with invalid UTF-8")
 		return
 	}
 
-	// Process stack trace
-	processStackTrace(content, monitor, cfg)
-}
-
-// handleContentTooLarge logs when content is too large to process
-func handleContentTooLarge(content models.ClipboardContent, cfg *config.Config) {
-	if cfg.Output.Verbose {
-		log.Printf("Content too large (%d bytes), skipping", len(content.Content))
+	// Check if this looks like a stack trace, and clean it if so
+	cleaningStart := time.Now()
+	cleanResult, detected := cleanContent(content.Content, cfg.Parser.Dialect)
+	if !detected {
+		rt.logger.Debug("skipping non-stack-trace content")
+		return
 	}
+	rt.collector.CleaningLatency.Observe(time.Since(cleaningStart).Seconds())
+	rt.collector.TracesDetected.Inc()
+	rt.collector.FramesRemoved.Add(float64(cleanResult.Removed))
+	rt.collector.BytesSaved.Add(float64(cleanResult.BytesSaved))
+
+	// Process stack trace
+	processStackTrace(content, monitor, cfg, rt, cleanResult)
 }
 
 // processStackTrace handles the main stack trace processing logic
-func processStackTrace(content models.ClipboardContent, monitor *clipboard.Monitor, cfg *config.Config) {
-	// Clean the stack trace and get detailed results
-	cleanResult := parser.CleanResult(content.Content)
+func processStackTrace(content models.ClipboardContent, monitor *clipboard.Monitor, cfg *config.Config, rt deps, cleanResult models.CleanResult) {
+	rt.logger.Debug("detected stack trace, cleaning")
 
 	// Check if content actually changed
 	if cleanResult.Cleaned == content.Content {
-		handleUnchangedContent(content, cfg)
+		rt.logger.Debug("no changes needed, content is already clean")
 		return
 	}
 
 	// Update clipboard with cleaned content
 	if err := updateClipboard(monitor, &cleanResult); err != nil {
-		timestamp := GetTimestamp(content, cfg)
-		fmt.Fprintf(os.Stderr, "%sError: Failed to update clipboard: %v\n", timestamp, err)
-		fmt.Fprintf(os.Stderr, "%sThe cleaned content could not be written back to clipboard\n", timestamp)
+		rt.logger.Error("failed to update clipboard with cleaned content", tracelog.F("error", err.Error()))
 		return
 	}
 
-	// Show results
-	showCleaningResults(content, &cleanResult, cfg)
-}
-
-// updateClipboard updates the clipboard with cleaned content
-func updateClipboard(monitor *clipboard.Monitor, cleanResult *models.CleanResult) error {
-	return monitor.SetContent(cleanResult.Cleaned)
-}
-
-// handleUnchangedContent handles the case where content is already clean
-func handleUnchangedContent(content models.ClipboardContent, cfg *config.Config) {
-	if cfg.Output.Verbose {
-		timestamp := GetTimestamp(content, cfg)
-		fmt.Printf("%sNo changes needed - content is already clean\n", timestamp)
-	}
-}
-
-// showCleaningResults displays the results of cleaning a stack trace
-func showCleaningResults(content models.ClipboardContent, cleanResult *models.CleanResult, cfg *config.Config) {
-	timestamp := GetTimestamp(content, cfg)
-
-	if cfg.Output.Verbose {
-		stackType := getStackTraceType(cleanResult.ErrorInfo, content.Content)
-		fmt.Printf("%sðŸ” Detected %s stack trace, cleaning...\n", timestamp, stackType)
+	if rt.archiver != nil {
+		archiveCleanedTrace(rt, content, &cleanResult)
 	}
 
-	if !cfg.Output.Quiet {
-		showSuccessMessage(content, cleanResult, cfg)
-		showCompactStatistics(timestamp, cleanResult)
+	if rt.history != nil {
+		recordHistory(rt, content, &cleanResult)
 	}
 
-	if cfg.Output.Verbose {
-		showVerboseStatistics(timestamp, cleanResult)
-	}
+	// Show results
+	showCleaningResults(content, &cleanResult, rt)
 }
 
-// showSuccessMessage displays the success message with stack trace type
-func showSuccessMessage(content models.ClipboardContent, cleanResult *models.CleanResult, cfg *config.Config) {
-	timestamp := GetTimestamp(content, cfg)
-	stackType := getStackTraceType(cleanResult.ErrorInfo, content.Content)
-	fmt.Printf("%sâœ… %s stack trace cleaned and clipboard updated\n", timestamp, stackType)
-}
+// archiveCleanedTrace records a cleaned trace to the archive, logging (but not
+// failing the request on) any archive write error.
+func archiveCleanedTrace(rt deps, content models.ClipboardContent, cleanResult *models.CleanResult) {
+	stats := archive.Stats{
+		Timestamp:     content.Timestamp,
+		Format:        content.Format,
+		OriginalBytes: len(cleanResult.Original),
+		CleanedBytes:  len(cleanResult.Cleaned),
+		BytesSaved:    cleanResult.BytesSaved,
+		FramesRemoved: cleanResult.Removed,
+	}
 
-// GetTimestamp returns formatted timestamp if enabled in config
-func GetTimestamp(content models.ClipboardContent, cfg *config.Config) string {
-	if cfg.Output.ShowTimestamp {
-		return fmt.Sprintf("[%s] ", content.Timestamp.Format("15:04:05"))
+	if _, err := rt.archiver.Append(cleanResult.Original, cleanResult.Cleaned, stats); err != nil {
+		rt.logger.Warn("failed to archive cleaned trace", tracelog.F("error", err.Error()))
 	}
-	return ""
 }
 
-// showCompactStatistics displays compact statistics for cleaned content
-func showCompactStatistics(timestamp string, cleanResult *models.CleanResult) {
-	if cleanResult.Removed > 0 || cleanResult.BytesSaved > 0 {
-		fmt.Printf("%s   â€¢ ", timestamp)
+// recordHistory appends a cleaned trace to the history log, logging (but not
+// failing the request on) any write error.
+func recordHistory(rt deps, content models.ClipboardContent, cleanResult *models.CleanResult) {
+	entry := history.Entry{
+		Timestamp:     content.Timestamp,
+		Original:      cleanResult.Original,
+		Cleaned:       cleanResult.Cleaned,
+		Format:        content.Format,
+		Language:      cleanResult.Language,
+		BytesSaved:    cleanResult.BytesSaved,
+		FramesRemoved: cleanResult.Removed,
+	}
 
-		statsParts := BuildStatsParts(cleanResult)
-		fmt.Printf("%s\n", strings.Join(statsParts, ", "))
+	if err := rt.history.Append(entry); err != nil {
+		rt.logger.Warn("failed to record history entry", tracelog.F("error", err.Error()))
 	}
 }
 
-// showVerboseStatistics displays verbose statistics for cleaned content
-func showVerboseStatistics(timestamp string, cleanResult *models.CleanResult) {
-	fmt.Printf("%s   â€¢ ", timestamp)
-
-	statsParts := BuildStatsParts(cleanResult)
-	if len(statsParts) > 0 {
-		fmt.Printf("%s\n", strings.Join(statsParts, ", "))
-	} else {
-		fmt.Printf("No changes needed\n")
-	}
+// updateClipboard updates the clipboard with cleaned content
+func updateClipboard(monitor *clipboard.Monitor, cleanResult *models.CleanResult) error {
+	return monitor.SetContent(cleanResult.Cleaned)
 }
 
-// BuildStatsParts builds the statistics parts for display
-func BuildStatsParts(cleanResult *models.CleanResult) []string {
-	statsParts := []string{}
+// showCleaningResults logs a structured record summarizing a completed clean.
+func showCleaningResults(content models.ClipboardContent, cleanResult *models.CleanResult, rt deps) {
+	stackType := stackTypeForResult(cleanResult)
 
-	if cleanResult.Removed > 0 {
-		statsParts = append(statsParts, fmt.Sprintf("Removed %d repetitive frame%s", cleanResult.Removed, plural(cleanResult.Removed)))
+	fields := []tracelog.Field{
+		tracelog.F("type", stackType),
+		tracelog.F("removed", cleanResult.Removed),
+		tracelog.F("bytes_saved", cleanResult.BytesSaved),
+		tracelog.F("lines_before", cleanResult.LinesBefore),
+		tracelog.F("lines_after", cleanResult.LinesAfter),
+	}
+	if cleanResult.Language != "" {
+		fields = append(fields, tracelog.F("language", cleanResult.Language))
 	}
-
 	if cleanResult.BytesSaved > 0 {
 		percentage := float64(cleanResult.BytesSaved) / float64(len(cleanResult.Original)) * 100
-		statsParts = append(statsParts, fmt.Sprintf("saved %d bytes, %.1f%%", cleanResult.BytesSaved, percentage))
+		fields = append(fields, tracelog.F("bytes_saved_percent", percentage))
 	}
 
-	return statsParts
+	rt.logger.Info("stack trace cleaned and clipboard updated", fields...)
 }
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
-	"com.github/rethunk-tech/no-reaction/internal/config"
-	"com.github/rethunk-tech/no-reaction/internal/models"
-	"com.github/rethunk-tech/no-reaction/parser"
+	"com.github/rethunk-tech/tracetrim/internal/config"
+	tracelog "com.github/rethunk-tech/tracetrim/internal/log"
+	"com.github/rethunk-tech/tracetrim/internal/models"
+	"com.github/rethunk-tech/tracetrim/parser"
 )
 
 // Test the testable logic components without requiring Monitor mocks
@@ -252,14 +255,11 @@ func TestClipboardContentHandlingLogic(t *testing.T) {
 	}
 }
 
-// Test timestamp and statistics formatting
+// Test that a completed clean is logged as a structured record rather than a
+// formatted string, with the statistics available as fields.
 func TestTimestampAndStatisticsLogic(t *testing.T) {
-	cfg := &config.Config{
-		Output: config.OutputConfig{
-			ShowTimestamp: true,
-			Verbose:       true,
-		},
-	}
+	var buf bytes.Buffer
+	logger := tracelog.New(tracelog.Config{Out: &buf, Format: tracelog.FormatJSON, MinLevel: tracelog.LevelInfo})
 
 	content := models.ClipboardContent{
 		Content:   "test content",
@@ -267,32 +267,27 @@ func TestTimestampAndStatisticsLogic(t *testing.T) {
 		Format:    "text/plain",
 	}
 
-	// Test timestamp formatting
-	timestamp := getTimestamp(content, cfg)
-	expectedTimestamp := "[14:30:45] "
-	if timestamp != expectedTimestamp {
-		t.Errorf("Expected timestamp %q, got %q", expectedTimestamp, timestamp)
-	}
-
-	// Test statistics building
 	cleanResult := &models.CleanResult{
 		Removed:    5,
 		BytesSaved: 256,
 		Original:   "original content",
 	}
 
-	statsParts := buildStatsParts(cleanResult)
-	if len(statsParts) == 0 {
-		t.Error("Expected statistics parts, got empty slice")
+	showCleaningResults(content, cleanResult, deps{logger: logger})
+
+	var rec tracelog.Record
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("failed to parse logged record: %v", err)
 	}
 
-	// Check that statistics contain expected information
-	statsStr := strings.Join(statsParts, ", ")
-	if !strings.Contains(statsStr, "Removed 5 repetitive frame") {
-		t.Errorf("Expected statistics to contain removal info, got: %s", statsStr)
+	if rec.Level != "info" {
+		t.Errorf("Expected level %q, got %q", "info", rec.Level)
+	}
+	if got, want := rec.Fields["removed"], float64(5); got != want {
+		t.Errorf("Fields[removed] = %v, want %v", got, want)
 	}
-	if !strings.Contains(statsStr, "saved 256 bytes") {
-		t.Errorf("Expected statistics to contain bytes saved info, got: %s", statsStr)
+	if got, want := rec.Fields["bytes_saved"], float64(256); got != want {
+		t.Errorf("Fields[bytes_saved] = %v, want %v", got, want)
 	}
 }
 
@@ -0,0 +1,242 @@
+// Package archive writes a durable audit trail of cleaned stack traces to disk, so
+// TraceTrim can be used as a passive collector for post-mortem analysis rather than
+// a one-shot clipboard filter.
+//
+// Each cleaned trace is written as its own tar.gz bundle (gzip does not support
+// cheap append, so growing a single archive in place would mean re-compressing it
+// on every trace) laid out as YYYYMMDD/HHMMSS-<hash>/{original.txt,cleaned.txt,stats.json}.
+// A single index.json at the root of the archive directory summarizes every bundle
+// written so far.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Stats captures the per-trace statistics recorded alongside each archived bundle
+type Stats struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Format        string    `json:"format"`
+	OriginalBytes int       `json:"original_bytes"`
+	CleanedBytes  int       `json:"cleaned_bytes"`
+	BytesSaved    int       `json:"bytes_saved"`
+	FramesRemoved int       `json:"frames_removed"`
+}
+
+// IndexEntry is one row of the archive's root-level index.json
+type IndexEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Hash          string    `json:"hash"`
+	Bundle        string    `json:"bundle"`
+	Format        string    `json:"format"`
+	BytesSaved    int       `json:"bytes_saved"`
+	FramesRemoved int       `json:"frames_removed"`
+}
+
+// Writer owns the archive directory: creating bundles, tracking the index, and
+// pruning old bundles once the archive exceeds its configured size or retention.
+type Writer struct {
+	mu        sync.Mutex
+	dir       string
+	maxSize   int64
+	retention time.Duration
+	index     []IndexEntry
+}
+
+// NewWriter opens (creating if necessary) an archive rooted at dir. maxSize bounds
+// the total size of retained bundles in bytes (0 disables size-based pruning), and
+// retention bounds how long a bundle is kept (0 disables age-based pruning).
+func NewWriter(dir string, maxSize int64, retention time.Duration) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory %s: %w", dir, err)
+	}
+
+	w := &Writer{dir: dir, maxSize: maxSize, retention: retention}
+	if err := w.loadIndex(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) indexPath() string {
+	return filepath.Join(w.dir, "index.json")
+}
+
+func (w *Writer) loadIndex() error {
+	data, err := os.ReadFile(w.indexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read archive index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &w.index); err != nil {
+		return fmt.Errorf("failed to parse archive index: %w", err)
+	}
+
+	return nil
+}
+
+// saveIndexLocked persists the index; callers must hold w.mu.
+func (w *Writer) saveIndexLocked() error {
+	data, err := json.MarshalIndent(w.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive index: %w", err)
+	}
+
+	if err := os.WriteFile(w.indexPath(), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+
+	return nil
+}
+
+// Append writes original/cleaned/stats as a new tar.gz bundle, records it in the
+// index, and prunes old bundles per the writer's size/retention limits. It returns
+// the path to the newly written bundle.
+func (w *Writer) Append(original, cleaned string, stats Stats) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if stats.Timestamp.IsZero() {
+		stats.Timestamp = time.Now()
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(original)))[:12]
+	dayDir := stats.Timestamp.Format("20060102")
+	entryDir := fmt.Sprintf("%s-%s", stats.Timestamp.Format("150405"), hash)
+	bundleName := fmt.Sprintf("%s-%s.tar.gz", dayDir, entryDir)
+	bundlePath := filepath.Join(w.dir, bundleName)
+
+	if err := writeBundle(bundlePath, filepath.Join(dayDir, entryDir), original, cleaned, stats); err != nil {
+		return "", err
+	}
+
+	w.index = append(w.index, IndexEntry{
+		Timestamp:     stats.Timestamp,
+		Hash:          hash,
+		Bundle:        bundleName,
+		Format:        stats.Format,
+		BytesSaved:    stats.BytesSaved,
+		FramesRemoved: stats.FramesRemoved,
+	})
+
+	if err := w.saveIndexLocked(); err != nil {
+		return bundlePath, err
+	}
+
+	return bundlePath, w.pruneLocked()
+}
+
+// writeBundle creates a single tar.gz bundle containing original.txt, cleaned.txt
+// and stats.json under prefix.
+func writeBundle(bundlePath, prefix, original, cleaned string, stats Stats) error {
+	f, err := os.Create(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive bundle %s: %w", bundlePath, err)
+	}
+	defer f.Close() //nolint:errcheck // best-effort close after explicit flush below
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	statsJSON, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive stats: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"original.txt", []byte(original)},
+		{"cleaned.txt", []byte(cleaned)},
+		{"stats.json", statsJSON},
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    filepath.ToSlash(filepath.Join(prefix, file.name)),
+			Mode:    0o644,
+			Size:    int64(len(file.data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write tar header for %s: %w", file.name, err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write tar entry %s: %w", file.name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize tar stream for %s: %w", bundlePath, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finalize gzip stream for %s: %w", bundlePath, err)
+	}
+
+	return nil
+}
+
+// pruneLocked drops the oldest bundles once the archive exceeds maxSize or a
+// bundle's age exceeds retention. Callers must hold w.mu.
+func (w *Writer) pruneLocked() error {
+	if w.maxSize <= 0 && w.retention <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var kept []IndexEntry
+	var totalSize int64
+
+	// Walk newest-first so size-based pruning drops the oldest bundles first.
+	for i := len(w.index) - 1; i >= 0; i-- {
+		entry := w.index[i]
+
+		if w.retention > 0 && now.Sub(entry.Timestamp) > w.retention {
+			w.removeBundleFile(entry.Bundle)
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(w.dir, entry.Bundle))
+		if err != nil {
+			// Bundle file is already gone; drop its index entry too.
+			continue
+		}
+
+		if w.maxSize > 0 && totalSize+info.Size() > w.maxSize {
+			w.removeBundleFile(entry.Bundle)
+			continue
+		}
+
+		totalSize += info.Size()
+		kept = append(kept, entry)
+	}
+
+	// Restore chronological order after walking newest-first.
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	w.index = kept
+
+	return w.saveIndexLocked()
+}
+
+func (w *Writer) removeBundleFile(name string) {
+	if err := os.Remove(filepath.Join(w.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "archive: failed to prune bundle %s: %v\n", name, err)
+	}
+}
@@ -3,10 +3,14 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"com.github/rethunk-tech/tracetrim/parser"
 )
 
 const (
@@ -42,6 +46,93 @@ const (
 
 	// Default parser minimum stack trace length
 	DefaultMinStackTraceLength = 20
+
+	// Default metrics HTTP listen address
+	DefaultMetricsListenAddr = ":9090"
+
+	// Default metrics HTTP path
+	DefaultMetricsPath = "/metrics"
+
+	// Default interval between metrics pushes
+	DefaultMetricsPushInterval = 15 * time.Second
+
+	// Minimum interval between metrics pushes
+	MinMetricsPushInterval = 1 * time.Second
+
+	// Default maximum size of the archive directory (500MB)
+	DefaultArchiveMaxSize = 500 * 1024 * 1024
+
+	// Default archive retention (30 days)
+	DefaultArchiveRetention = 30 * 24 * time.Hour
+
+	// LogFormatText renders log output as human-readable lines
+	LogFormatText = "text"
+
+	// LogFormatJSON renders log output as newline-delimited JSON records
+	LogFormatJSON = "json"
+
+	// Default log output format
+	DefaultLogFormat = LogFormatText
+
+	// ScriptOutputText renders script mode's result as cleaned content only
+	ScriptOutputText = "text"
+
+	// ScriptOutputJSON renders script mode's result as a structured JSON object
+	ScriptOutputJSON = "json"
+
+	// ScriptOutputJUnit renders script mode's result as JUnit XML
+	ScriptOutputJUnit = "junit"
+
+	// ScriptOutputSarif renders script mode's result as a SARIF 2.1.0 log,
+	// one result per stack frame, for code-scanning dashboards
+	ScriptOutputSarif = "sarif"
+
+	// Default script mode output format
+	DefaultScriptOutputFormat = ScriptOutputText
+
+	// ScriptInputRaw treats script mode input as a single stack trace (or
+	// plain text passed through verbatim)
+	ScriptInputRaw = "raw"
+
+	// ScriptInputMarkdown treats script mode input as a Markdown document and
+	// cleans only its fenced code blocks, leaving surrounding prose untouched
+	ScriptInputMarkdown = "markdown"
+
+	// Default script mode input format
+	DefaultScriptInputFormat = ScriptInputRaw
+
+	// Default timeout stream mode waits for an in-flight request to finish
+	// before exiting on SIGINT/SIGTERM
+	DefaultStreamShutdownTimeout = 5 * time.Second
+
+	// DialectAuto picks a stack trace dialect automatically based on content
+	DialectAuto = "auto"
+
+	// Explicit stack trace dialects, matching the names parser.Dialect
+	// implementations register under.
+	DialectJS     = "js"
+	DialectReact  = "react"
+	DialectPython = "python"
+	DialectGo     = "go"
+	DialectJava   = "java"
+	DialectDotNet = "dotnet"
+	DialectRuby   = "ruby"
+
+	// Default stack trace dialect
+	DefaultDialect = DialectAuto
+
+	// Default maximum number of entries the history log retains
+	DefaultHistoryMaxEntries = 10000
+
+	// Default number of lines of source shown above and below the error line
+	// in a rendered code frame
+	DefaultCodeFrameContextLines = 2
+
+	// maxCustomPatterns bounds how many entries CustomPatternSpecs may contain
+	maxCustomPatterns = 50
+
+	// maxCustomPatternLength bounds a single CustomPatternSpecs entry's length
+	maxCustomPatternLength = 500
 )
 
 // Config holds all configuration for the application
@@ -61,6 +152,12 @@ type Config struct {
 	// Clipboard monitoring settings
 	Clipboard ClipboardConfig
 
+	// Metrics and monitoring settings
+	Metrics MetricsConfig
+
+	// Replayable history log settings
+	History HistoryConfig
+
 	// Script mode flag (simplified)
 	ScriptMode bool
 
@@ -75,6 +172,33 @@ type ClipboardConfig struct {
 
 	// MaxContentSize is the maximum clipboard content size to process (in bytes)
 	MaxContentSize int `mapstructure:"clipboard-max-content-size"`
+
+	// AcceptedFormats restricts which MIME types the monitor dispatches to
+	// callbacks and Events(); everything else is still readable via
+	// Platform.GetContentTyped but won't trigger the clean-and-write-back
+	// loop. Defaults to ["text/plain"] to preserve current behavior.
+	AcceptedFormats []string `mapstructure:"clipboard-accepted-formats"`
+}
+
+// MetricsConfig contains Prometheus metrics exporter configuration
+type MetricsConfig struct {
+	// Enabled turns on the /metrics HTTP endpoint
+	Enabled bool
+
+	// ListenAddr is the address the metrics HTTP server listens on
+	ListenAddr string
+
+	// Path is the HTTP path metrics are served on
+	Path string
+
+	// PushEnabled turns on periodically pushing metrics to PushURL
+	PushEnabled bool
+
+	// PushURL is the Pushgateway endpoint to push to
+	PushURL string
+
+	// PushInterval is how often to push metrics when PushEnabled is set
+	PushInterval time.Duration
 }
 
 // OutputConfig contains output and logging configuration
@@ -82,6 +206,9 @@ type OutputConfig struct {
 	// LogFile is the path to log file (empty for stdout)
 	LogFile string
 
+	// LogFormat controls how log records are rendered: "text" or "json"
+	LogFormat string
+
 	// Verbose enables detailed logging
 	Verbose bool
 
@@ -90,25 +217,115 @@ type OutputConfig struct {
 
 	// Quiet suppresses non-essential output
 	Quiet bool
+
+	// ArchivePath is the directory cleaned traces are archived to (empty disables archiving)
+	ArchivePath string
+
+	// ArchiveMaxSize is the maximum total size in bytes the archive directory may
+	// grow to before old bundles are pruned (0 disables size-based pruning)
+	ArchiveMaxSize int64
+
+	// ArchiveRetention is how long an archived bundle is kept before being pruned
+	// (0 disables age-based pruning)
+	ArchiveRetention time.Duration
+}
+
+// HistoryConfig contains settings for the replayable cleaned-trace history log
+type HistoryConfig struct {
+	// Dir is the directory the history log is written to (empty disables it)
+	Dir string
+
+	// MaxEntries is the maximum number of entries the history log retains
+	// (0 disables trimming)
+	MaxEntries int
+}
+
+// CodeFrameConfig contains settings for rendering a source excerpt around the
+// top frame's error position, similar to Vitest's error frame display.
+type CodeFrameConfig struct {
+	// ContextLines is how many lines of source to show above and below the
+	// error line (0 disables code frame rendering entirely).
+	ContextLines int
+
+	// NoColor suppresses ANSI colors in the rendered code frame, for output
+	// that's being piped rather than viewed in a terminal.
+	NoColor bool
 }
 
 // ParserConfig contains parser-specific configuration
 type ParserConfig struct {
-	// CustomPatterns allows adding custom regex patterns for stack trace detection
-	CustomPatterns []string
+	// CustomPatternSpecs adds custom regex patterns for stack trace detection,
+	// using klog's -vmodule syntax: a comma-separated list of
+	// "pattern=minLines" entries (e.g. "runtime\\.=3,net/http=1,mypkg\\.=2").
+	// minLines overrides MinStackLinesForDetection for content where that
+	// pattern is the highest-priority match (earlier entries win ties); a
+	// bare pattern with no "=minLines" falls back to MinStackLinesForDetection.
+	// A pattern may use the named capture groups "func", "file", "line", and
+	// "col" so a match feeds frame extraction (signatures, source location)
+	// the same way the built-in patterns do.
+	CustomPatternSpecs string `mapstructure:"parser-custom-patterns"`
 
 	// MinStackLinesForDetection minimum lines to consider content a stack trace
 	MinStackLinesForDetection int
 
 	// MinStackTraceLength minimum content length to consider for stack trace detection
 	MinStackTraceLength int
+
+	// Dialect forces a specific stack trace dialect ("js", "react", "python",
+	// "go", "java", "dotnet", "ruby") instead of automatic detection ("auto",
+	// the default).
+	Dialect string
+
+	// DisabledDialects excludes these dialects from automatic detection (an
+	// explicit Dialect selection above is unaffected). Useful when a
+	// permissive dialect (e.g. "js") false-positives on another project's
+	// traces and should be skipped rather than force-picking one dialect.
+	DisabledDialects []string
+
+	// SourceMapEnabled turns on source-map resolution for JS/TS frames,
+	// annotating cleaned frames with their original source position ("//
+	// originally at src/Foo.tsx:42:8") when a ".map" file is found.
+	SourceMapEnabled bool
+
+	// SourceMapSearchRoot is the directory local ".map" files are resolved
+	// relative to when the referenced file isn't an absolute path (empty
+	// resolves alongside the referenced file).
+	SourceMapSearchRoot string
+
+	// SourceMapAllowNetworkFetch permits fetching ".map" files over http(s)
+	// for frames that reference a URL instead of a local path.
+	SourceMapAllowNetworkFetch bool
+
+	// CodeFrame controls rendering a source excerpt around the top frame's
+	// error position.
+	CodeFrame CodeFrameConfig
 }
 
 // ScriptConfig contains script mode configuration
 type ScriptConfig struct {
-	// OutputFormat controls the output format in script mode
+	// OutputFormat controls how script mode renders its result: "text"
+	// (cleaned content only, the default), "json" (a structured object CI
+	// tooling can parse), "junit" (a <testsuite> with one <testcase> per
+	// detected stack trace, for report dashboards that ingest JUnit XML), or
+	// "sarif" (a SARIF 2.1.0 log with one result per stack frame, for
+	// code-scanning dashboards).
 	OutputFormat string
 
+	// InputFormat controls how script mode interprets its input: "raw" (the
+	// whole input is one stack trace, the default) or "markdown" (the input
+	// is a Markdown document; only its fenced code blocks are cleaned).
+	InputFormat string
+
+	// Stream enables the streaming NDJSON protocol: each line of STDIN is a
+	// request envelope and each line of STDOUT is a response envelope, so a
+	// single process can serve many traces instead of re-execing per paste.
+	Stream bool
+
+	// StreamShutdownTimeout bounds how long stream mode waits for an
+	// in-flight request to finish draining on SIGINT/SIGTERM before exiting
+	// anyway.
+	StreamShutdownTimeout time.Duration
+
 	// Enabled determines if script mode is active
 	Enabled bool
 
@@ -131,23 +348,51 @@ func DefaultConfig() *Config {
 		Clipboard: ClipboardConfig{
 			PollingInterval: DefaultPollingInterval,
 			MaxContentSize:  DefaultMaxContentSize, // 1MB
+			AcceptedFormats: []string{"text/plain"},
+		},
+		Metrics: MetricsConfig{
+			Enabled:      false,
+			ListenAddr:   DefaultMetricsListenAddr,
+			Path:         DefaultMetricsPath,
+			PushEnabled:  false,
+			PushInterval: DefaultMetricsPushInterval,
 		},
 		Output: OutputConfig{
-			Verbose:       false,
-			LogFile:       "",
-			ShowTimestamp: true,
-			Quiet:         false,
+			Verbose:          false,
+			LogFile:          "",
+			LogFormat:        DefaultLogFormat,
+			ShowTimestamp:    true,
+			Quiet:            false,
+			ArchivePath:      "",
+			ArchiveMaxSize:   DefaultArchiveMaxSize,
+			ArchiveRetention: DefaultArchiveRetention,
 		},
 		Parser: ParserConfig{
-			MinStackLinesForDetection: DefaultMinStackLines,
-			MinStackTraceLength:       DefaultMinStackTraceLength,
-			CustomPatterns:            []string{},
+			MinStackLinesForDetection:  DefaultMinStackLines,
+			MinStackTraceLength:        DefaultMinStackTraceLength,
+			CustomPatternSpecs:         "",
+			Dialect:                    DefaultDialect,
+			DisabledDialects:           []string{},
+			SourceMapEnabled:           false,
+			SourceMapSearchRoot:        "",
+			SourceMapAllowNetworkFetch: false,
+			CodeFrame: CodeFrameConfig{
+				ContextLines: DefaultCodeFrameContextLines,
+				NoColor:      false,
+			},
 		},
 		Script: ScriptConfig{
-			Enabled:         false,
-			OutputFormat:    "cleaned", // "cleaned", "json", "stats"
-			ShowStatistics:  true,
-			ExitCodeOnError: false,
+			Enabled:               false,
+			OutputFormat:          DefaultScriptOutputFormat,
+			InputFormat:           DefaultScriptInputFormat,
+			ShowStatistics:        true,
+			ExitCodeOnError:       false,
+			Stream:                false,
+			StreamShutdownTimeout: DefaultStreamShutdownTimeout,
+		},
+		History: HistoryConfig{
+			Dir:        "",
+			MaxEntries: DefaultHistoryMaxEntries,
 		},
 		App: AppConfig{
 			ConfigFile: "config.yaml",
@@ -182,6 +427,23 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	config, err := applyOverrides(v)
+	if err != nil {
+		return nil, err
+	}
+
+	setLive(config)
+
+	return config, nil
+}
+
+// applyOverrides builds a Config from defaults, unmarshals whatever v picked up
+// from its config file and environment variables on top of them, and finally
+// applies any flag values that were explicitly set, so flags take precedence
+// over both. It's shared by LoadConfig and the hot-reload handler registered
+// by EnableHotReload, which re-runs the same resolution whenever the config
+// file changes on disk.
+func applyOverrides(v *viper.Viper) (*Config, error) {
 	// Start with default config
 	config := DefaultConfig()
 
@@ -198,18 +460,33 @@ func LoadConfig() (*Config, error) {
 	if v.IsSet("clipboard-max-content-size") {
 		config.Clipboard.MaxContentSize = v.GetInt("clipboard-max-content-size")
 	}
+	if v.IsSet("clipboard-accepted-formats") {
+		config.Clipboard.AcceptedFormats = v.GetStringSlice("clipboard-accepted-formats")
+	}
 	if v.IsSet("verbose") {
 		config.Output.Verbose = v.GetBool("verbose")
 	}
 	if v.IsSet("log-file") {
 		config.Output.LogFile = v.GetString("log-file")
 	}
+	if v.IsSet("log-format") {
+		config.Output.LogFormat = v.GetString("log-format")
+	}
 	if v.IsSet("quiet") {
 		config.Output.Quiet = v.GetBool("quiet")
 	}
 	if v.IsSet("show-timestamp") {
 		config.Output.ShowTimestamp = v.GetBool("show-timestamp")
 	}
+	if v.IsSet("archive-path") {
+		config.Output.ArchivePath = v.GetString("archive-path")
+	}
+	if v.IsSet("archive-max-size") {
+		config.Output.ArchiveMaxSize = v.GetInt64("archive-max-size")
+	}
+	if v.IsSet("archive-retention") {
+		config.Output.ArchiveRetention = v.GetDuration("archive-retention")
+	}
 	if v.IsSet("parser-min-stack-lines") {
 		config.Parser.MinStackLinesForDetection = v.GetInt("parser-min-stack-lines")
 	}
@@ -217,7 +494,69 @@ func LoadConfig() (*Config, error) {
 		config.Parser.MinStackTraceLength = v.GetInt("parser-min-stack-trace-length")
 	}
 	if v.IsSet("parser-custom-patterns") {
-		config.Parser.CustomPatterns = v.GetStringSlice("parser-custom-patterns")
+		config.Parser.CustomPatternSpecs = v.GetString("parser-custom-patterns")
+	}
+	if v.IsSet("dialect") {
+		config.Parser.Dialect = v.GetString("dialect")
+	}
+	if v.IsSet("disabled-dialects") {
+		config.Parser.DisabledDialects = v.GetStringSlice("disabled-dialects")
+	}
+	if v.IsSet("source-map-enabled") {
+		config.Parser.SourceMapEnabled = v.GetBool("source-map-enabled")
+	}
+	if v.IsSet("source-map-search-root") {
+		config.Parser.SourceMapSearchRoot = v.GetString("source-map-search-root")
+	}
+	if v.IsSet("source-map-allow-network-fetch") {
+		config.Parser.SourceMapAllowNetworkFetch = v.GetBool("source-map-allow-network-fetch")
+	}
+	if v.IsSet("code-frame-context-lines") {
+		config.Parser.CodeFrame.ContextLines = v.GetInt("code-frame-context-lines")
+	}
+	if v.IsSet("code-frame-no-color") {
+		config.Parser.CodeFrame.NoColor = v.GetBool("code-frame-no-color")
+	}
+	if v.IsSet("history-dir") {
+		config.History.Dir = v.GetString("history-dir")
+	}
+	if v.IsSet("history-max-entries") {
+		config.History.MaxEntries = v.GetInt("history-max-entries")
+	}
+	if v.IsSet("metrics-enabled") {
+		config.Metrics.Enabled = v.GetBool("metrics-enabled")
+	}
+	if v.IsSet("metrics-listen-addr") {
+		config.Metrics.ListenAddr = v.GetString("metrics-listen-addr")
+	}
+	if v.IsSet("metrics-path") {
+		config.Metrics.Path = v.GetString("metrics-path")
+	}
+	if v.IsSet("metrics-push-enabled") {
+		config.Metrics.PushEnabled = v.GetBool("metrics-push-enabled")
+	}
+	if v.IsSet("metrics-push-url") {
+		config.Metrics.PushURL = v.GetString("metrics-push-url")
+	}
+	if v.IsSet("metrics-push-interval") {
+		config.Metrics.PushInterval = v.GetDuration("metrics-push-interval")
+	}
+	if v.IsSet("output-format") {
+		config.Script.OutputFormat = v.GetString("output-format")
+	}
+	if v.IsSet("json") && v.GetBool("json") {
+		// --json is shorthand for --output-format json; applied after the
+		// override above so it wins if both are set.
+		config.Script.OutputFormat = ScriptOutputJSON
+	}
+	if v.IsSet("input-format") {
+		config.Script.InputFormat = v.GetString("input-format")
+	}
+	if v.IsSet("stream") {
+		config.Script.Stream = v.GetBool("stream")
+	}
+	if v.IsSet("stream-shutdown-timeout") {
+		config.Script.StreamShutdownTimeout = v.GetDuration("stream-shutdown-timeout")
 	}
 	if v.IsSet("script-mode") {
 		config.ScriptMode = v.GetBool("script-mode")
@@ -234,13 +573,38 @@ func BindFlags() error {
 	pflag.String("config", "config.yaml", "Configuration file path")
 	pflag.Duration("clipboard-polling-interval", DefaultPollingInterval, "Clipboard polling interval")
 	pflag.Int("clipboard-max-content-size", DefaultMaxContentSize, "Maximum clipboard content size in bytes")
+	pflag.StringSlice("clipboard-accepted-formats", []string{"text/plain"}, "MIME types the monitor dispatches to callbacks (comma-separated); other formats are still readable via GetContentTyped")
 	pflag.Bool("verbose", false, "Enable verbose output")
 	pflag.String("log-file", "", "Log file path (empty for stdout)")
+	pflag.String("log-format", DefaultLogFormat, "Log output format: text or json")
 	pflag.Bool("quiet", false, "Suppress non-essential output")
 	pflag.Bool("show-timestamp", true, "Show timestamps in output")
+	pflag.String("archive-path", "", "Directory cleaned traces are archived to as tar.gz bundles (empty disables archiving)")
+	pflag.Int64("archive-max-size", DefaultArchiveMaxSize, "Maximum total size in bytes the archive directory may grow to before old bundles are pruned (0 disables size-based pruning)")
+	pflag.Duration("archive-retention", DefaultArchiveRetention, "How long an archived bundle is kept before being pruned (0 disables age-based pruning)")
 	pflag.Int("parser-min-stack-lines", DefaultMinStackLines, "Minimum stack lines for detection")
 	pflag.Int("parser-min-stack-trace-length", DefaultMinStackTraceLength, "Minimum stack trace length")
-	pflag.StringSlice("parser-custom-patterns", []string{}, "Custom regex patterns for stack trace detection")
+	pflag.String("parser-custom-patterns", "", "Custom stack trace detection patterns, klog -vmodule style: \"pattern=minLines,...\" (named groups \"func\", \"file\", \"line\", \"col\" feed frame extraction; a bare pattern with no \"=minLines\" uses --parser-min-stack-lines)")
+	pflag.String("dialect", DefaultDialect, "Stack trace dialect: auto, js, react, python, go, java, dotnet, or ruby")
+	pflag.StringSlice("disabled-dialects", []string{}, "Dialects to exclude from automatic detection (comma-separated); an explicit --dialect is unaffected")
+	pflag.Bool("source-map-enabled", false, "Resolve JS/TS frames through adjacent .map files and annotate them with their original source position")
+	pflag.String("source-map-search-root", "", "Directory local .map files are resolved relative to (empty resolves alongside the referenced file)")
+	pflag.Bool("source-map-allow-network-fetch", false, "Allow fetching .map files over http(s) for frames that reference a URL")
+	pflag.Int("code-frame-context-lines", DefaultCodeFrameContextLines, "Lines of source to show above and below the error line in a code frame (0 disables it)")
+	pflag.Bool("code-frame-no-color", false, "Suppress ANSI colors in rendered code frames, for output that's being piped")
+	pflag.String("history-dir", "", "Directory cleaned traces are recorded to for later replay (empty disables history)")
+	pflag.Int("history-max-entries", DefaultHistoryMaxEntries, "Maximum number of entries the history log retains (0 disables trimming)")
+	pflag.Bool("metrics-enabled", false, "Expose a Prometheus /metrics HTTP endpoint")
+	pflag.String("metrics-listen-addr", DefaultMetricsListenAddr, "Address the metrics HTTP server listens on")
+	pflag.String("metrics-path", DefaultMetricsPath, "HTTP path metrics are served on")
+	pflag.Bool("metrics-push-enabled", false, "Push metrics to a Pushgateway endpoint instead of (or in addition to) serving them")
+	pflag.String("metrics-push-url", "", "Pushgateway endpoint URL for metrics-push-enabled")
+	pflag.Duration("metrics-push-interval", DefaultMetricsPushInterval, "Interval between metrics pushes")
+	pflag.String("output-format", DefaultScriptOutputFormat, "Script mode output format: text, json, junit, or sarif")
+	pflag.Bool("json", false, "Shorthand for --output-format json")
+	pflag.String("input-format", DefaultScriptInputFormat, "Script mode input format: raw or markdown")
+	pflag.Bool("stream", false, "Enable streaming NDJSON protocol mode: one request envelope per STDIN line, one response envelope per STDOUT line")
+	pflag.Duration("stream-shutdown-timeout", DefaultStreamShutdownTimeout, "How long stream mode waits for an in-flight request to finish draining on shutdown")
 	pflag.Bool("script-mode", false, "Enable script mode (read from STDIN, write to STDOUT, then exit)")
 	pflag.Bool("auto-detect-script-mode", true, "Auto-detect script mode based on non-interactive environment")
 
@@ -287,16 +651,157 @@ func ValidateConfig(config *Config) error {
 		return fmt.Errorf("parser min stack trace length must be at most 10000")
 	}
 
-	// Validate custom patterns if provided
-	for i, pattern := range config.Parser.CustomPatterns {
-		if pattern == "" {
-			return fmt.Errorf("custom pattern at index %d cannot be empty", i)
+	switch config.Parser.Dialect {
+	case "", DialectAuto, DialectJS, DialectReact, DialectPython, DialectGo, DialectJava, DialectDotNet, DialectRuby:
+	default:
+		return fmt.Errorf("dialect must be one of %q, %q, %q, %q, %q, %q, %q, or %q",
+			DialectAuto, DialectJS, DialectReact, DialectPython, DialectGo, DialectJava, DialectDotNet, DialectRuby)
+	}
+	for _, name := range config.Parser.DisabledDialects {
+		switch name {
+		case DialectJS, DialectReact, DialectPython, DialectGo, DialectJava, DialectDotNet, DialectRuby:
+		default:
+			return fmt.Errorf("disabled dialect %q must be one of %q, %q, %q, %q, %q, %q, or %q",
+				name, DialectJS, DialectReact, DialectPython, DialectGo, DialectJava, DialectDotNet, DialectRuby)
+		}
+	}
+
+	// Validate metrics configuration
+	if config.Metrics.Enabled && config.Metrics.ListenAddr == "" {
+		return fmt.Errorf("metrics listen address cannot be empty when metrics are enabled")
+	}
+	if config.Metrics.PushEnabled {
+		if config.Metrics.PushURL == "" {
+			return fmt.Errorf("metrics push URL cannot be empty when metrics push is enabled")
+		}
+		if config.Metrics.PushInterval < MinMetricsPushInterval {
+			return fmt.Errorf("metrics push interval must be at least %v", MinMetricsPushInterval)
+		}
+	}
+
+	// Validate log configuration
+	if config.Output.LogFormat != "" && config.Output.LogFormat != LogFormatText && config.Output.LogFormat != LogFormatJSON {
+		return fmt.Errorf("log format must be %q or %q", LogFormatText, LogFormatJSON)
+	}
+
+	// Validate script mode configuration
+	switch config.Script.OutputFormat {
+	case "", ScriptOutputText, ScriptOutputJSON, ScriptOutputJUnit, ScriptOutputSarif:
+	default:
+		return fmt.Errorf("script output format must be %q, %q, %q, or %q", ScriptOutputText, ScriptOutputJSON, ScriptOutputJUnit, ScriptOutputSarif)
+	}
+	switch config.Script.InputFormat {
+	case "", ScriptInputRaw, ScriptInputMarkdown:
+	default:
+		return fmt.Errorf("script input format must be %q or %q", ScriptInputRaw, ScriptInputMarkdown)
+	}
+	// Zero means "not set" and falls back to DefaultStreamShutdownTimeout
+	// at use time, so only a negative value is invalid here.
+	if config.Script.StreamShutdownTimeout < 0 {
+		return fmt.Errorf("script stream shutdown timeout cannot be negative")
+	}
+
+	// Validate archive configuration
+	if config.Output.ArchivePath != "" {
+		if config.Output.ArchiveMaxSize < 0 {
+			return fmt.Errorf("archive max size cannot be negative")
 		}
-		// Validate that pattern is a valid regex by attempting to compile it
-		if _, err := regexp.Compile(pattern); err != nil {
-			return fmt.Errorf("custom pattern at index %d is not a valid regex: %w", i, err)
+		if config.Output.ArchiveRetention < 0 {
+			return fmt.Errorf("archive retention cannot be negative")
 		}
 	}
 
+	// Validate history configuration
+	if config.History.Dir != "" && config.History.MaxEntries < 0 {
+		return fmt.Errorf("history max entries cannot be negative")
+	}
+
+	// Validate code frame configuration
+	if config.Parser.CodeFrame.ContextLines < 0 {
+		return fmt.Errorf("code frame context lines cannot be negative")
+	}
+
+	// Validate CustomPatternSpecs up front so a malformed spec is caught at
+	// startup rather than at first use. The count and length limits are a
+	// coarse complexity budget: Go's regexp engine (RE2) can't backtrack
+	// catastrophically like V8/irregexp, but a very long pattern or a very
+	// large set still costs compile time and per-line matching time.
+	if _, err := parseCustomPatternSpecs(config.Parser.CustomPatternSpecs); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// customPatternSpec is a single parsed "pattern=minLines" entry from
+// ParserConfig.CustomPatternSpecs. It exists in config purely to validate the
+// spec string up front; the parser package parses the same syntax again (see
+// parser.ParseCustomPatternSpecs) to build the compiled matchers it actually
+// runs, since parser doesn't import config.
+type customPatternSpec struct {
+	pattern  string
+	minLines int
+	hasLevel bool
+}
+
+// parseCustomPatternSpecs splits raw on commas into "pattern" or
+// "pattern=minLines" tokens, compiling each pattern to validate it and
+// parsing minLines as an integer when present. It returns the offending
+// index and token in the error for a malformed entry. Duplicate named
+// capture groups are rejected via parser.DuplicateSubexpName, the same check
+// parser.ParseCustomPatternSpecs runs at load time, so a spec that passes
+// ValidateConfig can't still crash the parser at runtime.
+func parseCustomPatternSpecs(raw string) ([]customPatternSpec, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	tokens := strings.Split(raw, ",")
+	if len(tokens) > maxCustomPatterns {
+		return nil, fmt.Errorf("too many custom patterns: %d exceeds the limit of %d", len(tokens), maxCustomPatterns)
+	}
+
+	specs := make([]customPatternSpec, 0, len(tokens))
+	for i, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) cannot be empty", i, token)
+		}
+		if len(token) > maxCustomPatternLength {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) exceeds the %d character limit", i, token, maxCustomPatternLength)
+		}
+
+		pattern, minLines, hasLevel := splitPatternLevel(token)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) is not a valid regex: %w", i, token, err)
+		}
+		if dup := parser.DuplicateSubexpName(re); dup != "" {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) uses named capture group %q more than once", i, token, dup)
+		}
+		if hasLevel && (minLines < MinStackLines || minLines > MaxStackLines) {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) has minLines %d outside the valid range [%d, %d]", i, token, minLines, MinStackLines, MaxStackLines)
+		}
+
+		specs = append(specs, customPatternSpec{pattern: pattern, minLines: minLines, hasLevel: hasLevel})
+	}
+
+	return specs, nil
+}
+
+// splitPatternLevel splits a "pattern=minLines" token on its last "=". If
+// what follows isn't a valid integer, the whole token is treated as a bare
+// pattern (so a regex that itself contains "=" still works).
+func splitPatternLevel(token string) (pattern string, minLines int, hasLevel bool) {
+	idx := strings.LastIndex(token, "=")
+	if idx < 0 {
+		return token, 0, false
+	}
+
+	n, err := strconv.Atoi(token[idx+1:])
+	if err != nil {
+		return token, 0, false
+	}
+
+	return token[:idx], n, true
+}
@@ -19,6 +19,10 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected max content size 1MB, got %d", cfg.Clipboard.MaxContentSize)
 	}
 
+	if len(cfg.Clipboard.AcceptedFormats) != 1 || cfg.Clipboard.AcceptedFormats[0] != "text/plain" {
+		t.Errorf("Expected accepted formats [text/plain], got %v", cfg.Clipboard.AcceptedFormats)
+	}
+
 	// Test output defaults
 	if cfg.Output.Verbose != false {
 		t.Errorf("Expected verbose false, got %v", cfg.Output.Verbose)
@@ -45,8 +49,8 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected min stack trace length 20, got %d", cfg.Parser.MinStackTraceLength)
 	}
 
-	if len(cfg.Parser.CustomPatterns) != 0 {
-		t.Errorf("Expected empty custom patterns, got %v", cfg.Parser.CustomPatterns)
+	if cfg.Parser.CustomPatternSpecs != "" {
+		t.Errorf("Expected empty custom pattern specs, got %q", cfg.Parser.CustomPatternSpecs)
 	}
 
 	// Test app defaults
@@ -122,6 +126,66 @@ func TestValidateConfig(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "Valid custom pattern specs",
+			config: &Config{
+				Clipboard: ClipboardConfig{
+					PollingInterval: 500 * time.Millisecond,
+					MaxContentSize:  1024 * 1024,
+				},
+				Parser: ParserConfig{
+					MinStackLinesForDetection: 2,
+					MinStackTraceLength:       20,
+					CustomPatternSpecs:        `runtime\.=3,net/http=1,mypkg\.=2`,
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "Custom pattern spec with malformed regex",
+			config: &Config{
+				Clipboard: ClipboardConfig{
+					PollingInterval: 500 * time.Millisecond,
+					MaxContentSize:  1024 * 1024,
+				},
+				Parser: ParserConfig{
+					MinStackLinesForDetection: 2,
+					MinStackTraceLength:       20,
+					CustomPatternSpecs:        "(",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Custom pattern spec with out-of-range minLines",
+			config: &Config{
+				Clipboard: ClipboardConfig{
+					PollingInterval: 500 * time.Millisecond,
+					MaxContentSize:  1024 * 1024,
+				},
+				Parser: ParserConfig{
+					MinStackLinesForDetection: 2,
+					MinStackTraceLength:       20,
+					CustomPatternSpecs:        "runtime=0",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Custom pattern spec with duplicate named capture group",
+			config: &Config{
+				Clipboard: ClipboardConfig{
+					PollingInterval: 500 * time.Millisecond,
+					MaxContentSize:  1024 * 1024,
+				},
+				Parser: ParserConfig{
+					MinStackLinesForDetection: 2,
+					MinStackTraceLength:       20,
+					CustomPatternSpecs:        `(?P<name>foo)(?P<name>bar)`,
+				},
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,7 +217,7 @@ func TestConfigStructCreation(t *testing.T) {
 		Parser: ParserConfig{
 			MinStackLinesForDetection: 5,
 			MinStackTraceLength:       50,
-			CustomPatterns:            []string{"pattern1", "pattern2"},
+			CustomPatternSpecs:        "pattern1,pattern2=3",
 		},
 		App: AppConfig{
 			ConfigFile: "custom.yaml",
@@ -172,8 +236,8 @@ func TestConfigStructCreation(t *testing.T) {
 		t.Errorf("Expected min stack lines 5, got %d", cfg.Parser.MinStackLinesForDetection)
 	}
 
-	if len(cfg.Parser.CustomPatterns) != 2 {
-		t.Errorf("Expected 2 custom patterns, got %d", len(cfg.Parser.CustomPatterns))
+	if cfg.Parser.CustomPatternSpecs != "pattern1,pattern2=3" {
+		t.Errorf("Expected custom pattern specs %q, got %q", "pattern1,pattern2=3", cfg.Parser.CustomPatternSpecs)
 	}
 }
 
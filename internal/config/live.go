@@ -0,0 +1,107 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// live holds the current config so long-running code (the clipboard Monitor,
+// parser thresholds, log-file destination) can read it without restarting the
+// daemon. It's populated by LoadConfig and, once EnableHotReload is called,
+// kept up to date whenever the config file changes on disk.
+var live atomic.Pointer[Config]
+
+// Live returns the most recently loaded Config. LoadConfig stores its result
+// without validating it; hot reloads are validated before they replace it. It
+// returns nil until LoadConfig has run at least once.
+func Live() *Config {
+	return live.Load()
+}
+
+func setLive(cfg *Config) {
+	live.Store(cfg)
+}
+
+// configSubscriber is notified after Live() changes to a new, already-validated
+// Config.
+type configSubscriber struct {
+	id int
+	fn func(old, new *Config)
+}
+
+var (
+	subMu          sync.Mutex
+	subscribers    map[int]*configSubscriber
+	nextSubscriber int
+)
+
+// Subscribe registers fn to be called with the old and new Config whenever a
+// hot reload swaps Live() for a new one. It returns a subscription ID for use
+// with Unsubscribe. fn is called synchronously from the reload goroutine, so
+// it should do its work quickly (e.g. recreate a ticker) rather than block.
+func Subscribe(fn func(old, new *Config)) int {
+	subMu.Lock()
+	defer subMu.Unlock()
+
+	if subscribers == nil {
+		subscribers = make(map[int]*configSubscriber)
+	}
+
+	nextSubscriber++
+	id := nextSubscriber
+	subscribers[id] = &configSubscriber{id: id, fn: fn}
+	return id
+}
+
+// Unsubscribe removes a subscription registered with Subscribe.
+func Unsubscribe(id int) {
+	subMu.Lock()
+	defer subMu.Unlock()
+
+	delete(subscribers, id)
+}
+
+func notifySubscribers(old, new *Config) {
+	subMu.Lock()
+	fns := make([]func(old, new *Config), 0, len(subscribers))
+	for _, sub := range subscribers {
+		fns = append(fns, sub.fn)
+	}
+	subMu.Unlock()
+
+	for _, fn := range fns {
+		fn(old, new)
+	}
+}
+
+// EnableHotReload starts watching the config file loaded by LoadConfig for
+// changes. On each change it re-resolves the config (file + environment +
+// flags, same as LoadConfig) and runs the result through ValidateConfig. A
+// valid reload atomically replaces Live() and notifies subscribers with the
+// old and new Config; an invalid one is logged and dropped, leaving the
+// previous config in effect. LoadConfig must be called before this.
+func EnableHotReload() {
+	v := viper.GetViper()
+
+	v.OnConfigChange(func(_ fsnotify.Event) {
+		reloaded, err := applyOverrides(v)
+		if err != nil {
+			log.Printf("config: hot reload failed to parse config, keeping previous config: %v", err)
+			return
+		}
+
+		if err := ValidateConfig(reloaded); err != nil {
+			log.Printf("config: hot reload produced an invalid config, keeping previous config: %v", err)
+			return
+		}
+
+		old := Live()
+		setLive(reloaded)
+		notifySubscribers(old, reloaded)
+	})
+	v.WatchConfig()
+}
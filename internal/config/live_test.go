@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestSubscribeIsNotifiedOnReload(t *testing.T) {
+	first := DefaultConfig()
+	setLive(first)
+
+	var gotOld, gotNew *Config
+	id := Subscribe(func(old, new *Config) {
+		gotOld, gotNew = old, new
+	})
+	defer Unsubscribe(id)
+
+	second := DefaultConfig()
+	second.Clipboard.PollingInterval = 42
+
+	setLive(second)
+	notifySubscribers(first, second)
+
+	if gotOld != first {
+		t.Errorf("subscriber received old = %p, want %p", gotOld, first)
+	}
+	if gotNew != second {
+		t.Errorf("subscriber received new = %p, want %p", gotNew, second)
+	}
+	if Live() != second {
+		t.Error("Live() should return the config passed to the most recent setLive")
+	}
+}
+
+func TestUnsubscribeStopsNotifications(t *testing.T) {
+	called := false
+	id := Subscribe(func(old, new *Config) {
+		called = true
+	})
+	Unsubscribe(id)
+
+	notifySubscribers(DefaultConfig(), DefaultConfig())
+
+	if called {
+		t.Error("unsubscribed callback should not be invoked")
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Snapshot captures process-global configuration state so a test can restore
+// it afterward. LoadConfig and BindFlags both mutate state that lives outside
+// any single *Config value (the global viper instance and pflag.CommandLine),
+// so a table-driven test that calls either of them needs a way to undo that
+// mutation without forking a subprocess per case. The approach mirrors klog's
+// save/restore-state tests: capture everything up front, then reset it all
+// atomically in one Restore call.
+type Snapshot struct {
+	config     *Config
+	configCopy Config
+
+	viper *viperSnapshot
+	flags *flagSnapshot
+}
+
+// TakeSnapshot captures cfg (via a deep copy), the global viper instance, and
+// pflag.CommandLine. Call Restore on the returned Snapshot, typically via
+// t.Cleanup(snap.Restore), to undo any changes made to any of the three
+// before the next test runs.
+func TakeSnapshot(cfg *Config) *Snapshot {
+	return &Snapshot{
+		config:     cfg,
+		configCopy: cfg.deepCopy(),
+		viper:      takeViperSnapshot(viper.GetViper()),
+		flags:      takeFlagSnapshot(pflag.CommandLine),
+	}
+}
+
+// Restore resets cfg, the global viper instance, and pflag.CommandLine back
+// to the state they were in when the Snapshot was taken.
+func (s *Snapshot) Restore() {
+	*s.config = s.configCopy
+	s.viper.restore(viper.GetViper())
+	s.flags.restore(pflag.CommandLine)
+}
+
+// deepCopy returns a copy of c whose slice fields don't alias c's.
+func (c *Config) deepCopy() Config {
+	out := *c
+	out.Clipboard.AcceptedFormats = append([]string(nil), c.Clipboard.AcceptedFormats...)
+	out.Parser.DisabledDialects = append([]string(nil), c.Parser.DisabledDialects...)
+	return out
+}
+
+// viperSnapshot is the subset of a *viper.Viper's state that LoadConfig reads
+// or mutates: the settings visible through v.AllSettings(), regardless of
+// whether they came from a config file, the environment, or v.Set.
+type viperSnapshot struct {
+	settings map[string]interface{}
+}
+
+func takeViperSnapshot(v *viper.Viper) *viperSnapshot {
+	return &viperSnapshot{settings: v.AllSettings()}
+}
+
+// restore replaces v's settings with the snapshotted ones. viper has no
+// public API for removing a key once set, so this resets v to a fresh
+// instance first and then replays the captured settings on top of it.
+func (vs *viperSnapshot) restore(v *viper.Viper) {
+	*v = *viper.New()
+	for key, value := range vs.settings {
+		v.Set(key, value)
+	}
+}
+
+// flagSnapshot is the subset of a *pflag.FlagSet's state that BindFlags
+// defines and pflag.Parse mutates: each flag's current value, default value,
+// and whether it was explicitly set on the command line.
+type flagSnapshot struct {
+	values map[string]flagValueSnapshot
+}
+
+type flagValueSnapshot struct {
+	value    string
+	defValue string
+	changed  bool
+}
+
+func takeFlagSnapshot(fs *pflag.FlagSet) *flagSnapshot {
+	values := make(map[string]flagValueSnapshot)
+	fs.VisitAll(func(f *pflag.Flag) {
+		values[f.Name] = flagValueSnapshot{
+			value:    f.Value.String(),
+			defValue: f.DefValue,
+			changed:  f.Changed,
+		}
+	})
+	return &flagSnapshot{values: values}
+}
+
+// restore resets every flag defined on fs back to the value and Changed state
+// it had when the snapshot was taken. Flags defined after the snapshot (e.g.
+// by a test calling BindFlags a second time) are left as-is; BindFlags only
+// ever defines the same fixed set of flags, so this doesn't come up in
+// practice.
+func (fs *flagSnapshot) restore(flagSet *pflag.FlagSet) {
+	flagSet.VisitAll(func(f *pflag.Flag) {
+		saved, ok := fs.values[f.Name]
+		if !ok {
+			return
+		}
+		_ = f.Value.Set(saved.defValue)
+		_ = f.Value.Set(saved.value)
+		f.Changed = saved.changed
+	})
+}
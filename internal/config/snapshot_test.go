@@ -0,0 +1,83 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestSnapshotRestoresConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	snap := TakeSnapshot(cfg)
+
+	cfg.Clipboard.PollingInterval = 42
+	cfg.Clipboard.AcceptedFormats = append(cfg.Clipboard.AcceptedFormats, "image/png")
+
+	snap.Restore()
+
+	if cfg.Clipboard.PollingInterval != DefaultPollingInterval {
+		t.Errorf("Restore() left PollingInterval = %v, want %v", cfg.Clipboard.PollingInterval, DefaultPollingInterval)
+	}
+	if len(cfg.Clipboard.AcceptedFormats) != 1 || cfg.Clipboard.AcceptedFormats[0] != "text/plain" {
+		t.Errorf("Restore() left AcceptedFormats = %v, want [text/plain]", cfg.Clipboard.AcceptedFormats)
+	}
+}
+
+func TestSnapshotRestoreDoesNotAliasOriginalSlice(t *testing.T) {
+	cfg := DefaultConfig()
+	snap := TakeSnapshot(cfg)
+
+	// Mutating the slice in place (not just reassigning the field) must not
+	// reach back into the snapshot's copy.
+	cfg.Clipboard.AcceptedFormats[0] = "mutated"
+
+	snap.Restore()
+
+	if cfg.Clipboard.AcceptedFormats[0] != "text/plain" {
+		t.Errorf("Restore() = %v, want unmutated [text/plain]", cfg.Clipboard.AcceptedFormats)
+	}
+}
+
+func TestSnapshotRestoresViperSettings(t *testing.T) {
+	v := viper.GetViper()
+	v.Set("quiet", false)
+
+	snap := TakeSnapshot(DefaultConfig())
+
+	v.Set("quiet", true)
+	v.Set("a-key-that-did-not-exist-before", "surprise")
+
+	snap.Restore()
+
+	if viper.GetViper().GetBool("quiet") {
+		t.Error("Restore() left quiet = true, want the pre-snapshot value")
+	}
+	if viper.GetViper().IsSet("a-key-that-did-not-exist-before") {
+		t.Error("Restore() should have dropped a key set after the snapshot was taken")
+	}
+}
+
+func TestSnapshotRestoresPflagCommandLine(t *testing.T) {
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pflag.String("greeting", "hello", "a test flag")
+
+	snap := TakeSnapshot(DefaultConfig())
+
+	if err := pflag.CommandLine.Set("greeting", "goodbye"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	snap.Restore()
+
+	f := pflag.CommandLine.Lookup("greeting")
+	if f == nil {
+		t.Fatal("expected \"greeting\" flag to still be defined after Restore()")
+	}
+	if f.Value.String() != "hello" {
+		t.Errorf("Restore() left greeting = %q, want %q", f.Value.String(), "hello")
+	}
+	if f.Changed {
+		t.Error("Restore() left Changed = true, want false")
+	}
+}
@@ -0,0 +1,188 @@
+// Package history persists every cleaned trace to a rotating on-disk JSON
+// lines log, so a later dialect or dedup-rule improvement can be replayed
+// against past traces without hunting them down. This is modeled on the way
+// reproducer frameworks (e.g. syzkaller's repro.Result) persist a program,
+// its stats, and its report for later re-execution.
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// logFileName is the single rotating log file within a history directory.
+const logFileName = "history.jsonl"
+
+// Entry is one cleaned trace recorded to the history log.
+type Entry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Hash          string    `json:"hash"`
+	Original      string    `json:"original"`
+	Cleaned       string    `json:"cleaned"`
+	Format        string    `json:"format"`
+	Language      string    `json:"language"`
+	BytesSaved    int       `json:"bytes_saved"`
+	FramesRemoved int       `json:"frames_removed"`
+}
+
+// Stats aggregates bytes saved and frames removed across a set of entries.
+type Stats struct {
+	Count              int
+	TotalBytesSaved    int
+	TotalFramesRemoved int
+}
+
+// Writer appends Entry records to a JSON-lines log, trimming the oldest
+// entries once the log exceeds maxEntries. Unlike archive.Writer's tar.gz
+// bundles, entries are cheap enough to rewrite wholesale on trim.
+type Writer struct {
+	mu         sync.Mutex
+	path       string
+	maxEntries int
+}
+
+// NewWriter opens (creating if necessary) a history log rooted at dir.
+// maxEntries bounds how many entries the log retains (0 disables trimming).
+func NewWriter(dir string, maxEntries int) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory %s: %w", dir, err)
+	}
+
+	return &Writer{path: filepath.Join(dir, logFileName), maxEntries: maxEntries}, nil
+}
+
+// Append records entry, computing its hash from Original if not already set,
+// and trims the log down to maxEntries if it now exceeds that limit.
+func (w *Writer) Append(entry Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Hash == "" {
+		entry.Hash = fmt.Sprintf("%x", sha256.Sum256([]byte(entry.Original)))[:12]
+	}
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history log %s: %w", w.path, err)
+	}
+	defer f.Close() //nolint:errcheck // best-effort close after explicit write below
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to append history entry: %w", err)
+	}
+
+	if w.maxEntries <= 0 {
+		return nil
+	}
+
+	return w.trimLocked()
+}
+
+// trimLocked rewrites the log keeping only the newest maxEntries records.
+// Callers must hold w.mu.
+func (w *Writer) trimLocked() error {
+	entries, err := readEntries(w.path)
+	if err != nil {
+		return err
+	}
+	if len(entries) <= w.maxEntries {
+		return nil
+	}
+	entries = entries[len(entries)-w.maxEntries:]
+
+	tmp := w.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite history log: %w", err)
+	}
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			f.Close() //nolint:errcheck // best-effort close on the error path
+			return fmt.Errorf("failed to marshal history entry: %w", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			f.Close() //nolint:errcheck // best-effort close on the error path
+			return fmt.Errorf("failed to rewrite history log: %w", err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to finalize history log: %w", err)
+	}
+
+	return os.Rename(tmp, w.path)
+}
+
+// Entries reads every entry recorded in dir's history log, oldest first.
+func Entries(dir string) ([]Entry, error) {
+	return readEntries(filepath.Join(dir, logFileName))
+}
+
+func readEntries(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history log %s: %w", path, err)
+	}
+	defer f.Close() //nolint:errcheck // read-only handle, nothing to flush
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse history entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history log %s: %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// Since filters entries to those recorded within d of now.
+func Since(entries []Entry, d time.Duration) []Entry {
+	cutoff := time.Now().Add(-d)
+	var kept []Entry
+	for _, entry := range entries {
+		if entry.Timestamp.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// Aggregate sums bytes saved and frames removed across entries.
+func Aggregate(entries []Entry) Stats {
+	stats := Stats{Count: len(entries)}
+	for _, entry := range entries {
+		stats.TotalBytesSaved += entry.BytesSaved
+		stats.TotalFramesRemoved += entry.FramesRemoved
+	}
+	return stats
+}
@@ -0,0 +1,227 @@
+// Package log provides TraceTrim's structured logger: leveled, key-value
+// records that render as human-readable text or newline-delimited JSON, so
+// operators can pipe TraceTrim's output into journald, Loki, or ELK instead
+// of scraping formatted strings.
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log record, ordered from least to most severe.
+type Level int
+
+// Levels a Logger can emit at or filter by.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the lowercase name of the level, as used in rendered records.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Format selects how records are rendered.
+type Format string
+
+// Supported output formats.
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// Field is a single key-value pair attached to a log record.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F is a convenience constructor for a Field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Record is a single structured log entry. It is the unit tests assert
+// against so assertions don't depend on a particular rendered string.
+type Record struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	// Stack holds a pkg/errors-style frame list, populated for Error records
+	// when the Logger was configured with Tracing enabled.
+	Stack []string `json:"stack,omitempty"`
+}
+
+// Config controls how a Logger renders and filters records.
+type Config struct {
+	// Out is the destination records are written to.
+	Out io.Writer
+
+	// Format selects text or JSON rendering.
+	Format Format
+
+	// MinLevel suppresses records below this severity.
+	MinLevel Level
+
+	// ShowTimestamp includes each record's time in text-format output (JSON
+	// records always carry Record.Time).
+	ShowTimestamp bool
+
+	// Tracing captures a call-stack snapshot on every Error record, so a
+	// failure can be diagnosed without reproducing it.
+	Tracing bool
+}
+
+// Logger is a leveled, structured logger that writes Records to an io.Writer
+// as either text or JSON.
+type Logger struct {
+	mu            sync.Mutex
+	out           io.Writer
+	format        Format
+	minLevel      Level
+	showTimestamp bool
+	tracing       bool
+}
+
+// New creates a Logger from cfg.
+func New(cfg Config) *Logger {
+	return &Logger{
+		out:           cfg.Out,
+		format:        cfg.Format,
+		minLevel:      cfg.MinLevel,
+		showTimestamp: cfg.ShowTimestamp,
+		tracing:       cfg.Tracing,
+	}
+}
+
+// Debug logs msg at LevelDebug with the given fields.
+func (l *Logger) Debug(msg string, fields ...Field) { l.log(LevelDebug, msg, fields) }
+
+// Info logs msg at LevelInfo with the given fields.
+func (l *Logger) Info(msg string, fields ...Field) { l.log(LevelInfo, msg, fields) }
+
+// Warn logs msg at LevelWarn with the given fields.
+func (l *Logger) Warn(msg string, fields ...Field) { l.log(LevelWarn, msg, fields) }
+
+// Error logs msg at LevelError with the given fields. When the Logger was
+// configured with Tracing enabled, the record also carries the current call
+// stack.
+func (l *Logger) Error(msg string, fields ...Field) { l.log(LevelError, msg, fields) }
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	if l == nil || level < l.minLevel {
+		return
+	}
+
+	rec := Record{
+		Time:    time.Now(),
+		Level:   level.String(),
+		Message: msg,
+	}
+
+	if len(fields) > 0 {
+		rec.Fields = make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			rec.Fields[f.Key] = f.Value
+		}
+	}
+
+	if l.tracing && level == LevelError {
+		rec.Stack = captureStack(3)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write(rec)
+}
+
+func (l *Logger) write(rec Record) {
+	if l.out == nil {
+		return
+	}
+
+	if l.format == FormatJSON {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(l.out, string(data))
+		return
+	}
+
+	l.writeText(rec)
+}
+
+func (l *Logger) writeText(rec Record) {
+	var b strings.Builder
+
+	if l.showTimestamp {
+		fmt.Fprintf(&b, "[%s] ", rec.Time.Format("15:04:05"))
+	}
+	fmt.Fprintf(&b, "%s %s", strings.ToUpper(rec.Level), rec.Message)
+
+	for _, key := range sortedFieldKeys(rec.Fields) {
+		fmt.Fprintf(&b, " %s=%v", key, rec.Fields[key])
+	}
+
+	fmt.Fprintln(l.out, b.String())
+
+	for _, frame := range rec.Stack {
+		fmt.Fprintf(l.out, "    %s\n", frame)
+	}
+}
+
+func sortedFieldKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// captureStack returns a pkg/errors-style list of "function\n\tfile:line"
+// frames for the goroutine's call stack, skipping the top skip frames (the
+// logger's own internals).
+func captureStack(skip int) []string {
+	const maxFrames = 32
+
+	pcs := make([]uintptr, maxFrames)
+	n := runtime.Callers(skip+1, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []string
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoggerJSONRecordFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Out: &buf, Format: FormatJSON, MinLevel: LevelInfo})
+
+	logger.Info("stack trace cleaned", F("removed", 5), F("bytes_saved", 256))
+
+	var rec Record
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("failed to parse logged record: %v", err)
+	}
+
+	if rec.Message != "stack trace cleaned" {
+		t.Errorf("Message = %q, want %q", rec.Message, "stack trace cleaned")
+	}
+	if rec.Level != "info" {
+		t.Errorf("Level = %q, want %q", rec.Level, "info")
+	}
+	if got, want := rec.Fields["removed"], float64(5); got != want {
+		t.Errorf("Fields[removed] = %v, want %v", got, want)
+	}
+	if got, want := rec.Fields["bytes_saved"], float64(256); got != want {
+		t.Errorf("Fields[bytes_saved] = %v, want %v", got, want)
+	}
+}
+
+func TestLoggerFiltersBelowMinLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Out: &buf, Format: FormatJSON, MinLevel: LevelWarn})
+
+	logger.Debug("should be suppressed")
+	logger.Info("should also be suppressed")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output below MinLevel, got: %s", buf.String())
+	}
+}
+
+func TestLoggerTracingCapturesStackOnError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Out: &buf, Format: FormatJSON, MinLevel: LevelInfo, Tracing: true})
+
+	logger.Error("clipboard update failed")
+
+	var rec Record
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("failed to parse logged record: %v", err)
+	}
+	if len(rec.Stack) == 0 {
+		t.Error("expected Tracing to populate Stack on an Error record")
+	}
+}
+
+func TestLoggerTextFormatIncludesTimestampAndFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Out: &buf, Format: FormatText, MinLevel: LevelInfo, ShowTimestamp: true})
+
+	logger.Info("test content", F("removed", 5))
+
+	line := buf.String()
+	if !strings.Contains(line, "INFO test content") {
+		t.Errorf("expected rendered line to contain level and message, got: %s", line)
+	}
+	if !strings.Contains(line, "removed=5") {
+		t.Errorf("expected rendered line to contain field, got: %s", line)
+	}
+}
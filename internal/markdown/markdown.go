@@ -0,0 +1,102 @@
+// Package markdown provides a minimal fenced-code-block walker so script
+// mode can clean stack traces embedded in prose (GitHub issues, Slack
+// exports) while leaving the surrounding Markdown untouched. It mirrors the
+// "find fenced blocks, act on their content" approach tools like mdrip use
+// to execute embedded code, applied here to cleaning instead.
+package markdown
+
+import "strings"
+
+// fenceMarkers are the two Markdown fence styles recognized; a closing fence
+// must reuse the same marker the block was opened with.
+var fenceMarkers = []string{"```", "~~~"}
+
+// Block describes one fenced code block found in a document, in the order
+// it appeared.
+type Block struct {
+	// Fence is the marker the block was opened with ("```" or "~~~"),
+	// including any language info string (e.g. "```go").
+	Fence string
+
+	// Original is the block's content, excluding the fence lines.
+	Original string
+
+	// Cleaned is what transform returned for this block.
+	Cleaned string
+
+	// Changed is true when transform reported the block's content differs.
+	Changed bool
+}
+
+// Transform walks doc's fenced code blocks and replaces each one's content
+// with the result of calling transform on it. transform returns the
+// (possibly unmodified) content and whether it changed; blocks it reports as
+// unchanged are left byte-for-byte identical, so Markdown the user didn't
+// ask to clean round-trips exactly. Transform returns the rewritten document
+// and a Block per fenced block found, for callers that want a per-block
+// summary.
+func Transform(doc string, transform func(content string) (cleaned string, changed bool)) (string, []Block) {
+	lines := strings.Split(doc, "\n")
+
+	var out []string
+	var blocks []Block
+	var buf []string
+	marker := ""  // the bare fence symbol ("```" or "~~~"), used to match the close
+	opening := "" // the full opening line, including any language info string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if marker == "" {
+			if m := openingFence(trimmed); m != "" {
+				marker = m
+				opening = trimmed
+				buf = nil
+				out = append(out, line)
+				continue
+			}
+			out = append(out, line)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, marker) {
+			original := strings.Join(buf, "\n")
+			cleaned, changed := transform(original)
+
+			block := Block{Fence: opening, Original: original, Cleaned: cleaned, Changed: changed}
+			blocks = append(blocks, block)
+
+			if changed {
+				if cleaned != "" {
+					out = append(out, strings.Split(cleaned, "\n")...)
+				}
+			} else {
+				out = append(out, buf...)
+			}
+			out = append(out, line)
+			marker = ""
+			continue
+		}
+
+		buf = append(buf, line)
+	}
+
+	// An unterminated fence has no matching close; flush its lines verbatim
+	// rather than silently dropping them.
+	if marker != "" {
+		out = append(out, buf...)
+	}
+
+	return strings.Join(out, "\n"), blocks
+}
+
+// openingFence returns the bare fence symbol ("```" or "~~~") trimmed opens
+// with, or "" if trimmed doesn't open a fenced code block.
+func openingFence(trimmed string) string {
+	for _, m := range fenceMarkers {
+		if strings.HasPrefix(trimmed, m) {
+			return m
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package markdown
+
+import "testing"
+
+func TestTransformCleansOnlyMatchingBlocks(t *testing.T) {
+	doc := "Before text\n\n```\nSTACK\n```\n\nAfter text\n\n```\nplain\n```\n"
+
+	rewritten, blocks := Transform(doc, func(content string) (string, bool) {
+		if content == "STACK" {
+			return "CLEANED", true
+		}
+		return content, false
+	})
+
+	want := "Before text\n\n```\nCLEANED\n```\n\nAfter text\n\n```\nplain\n```\n"
+	if rewritten != want {
+		t.Errorf("rewritten = %q, want %q", rewritten, want)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("len(blocks) = %d, want 2", len(blocks))
+	}
+	if !blocks[0].Changed || blocks[0].Cleaned != "CLEANED" {
+		t.Errorf("blocks[0] = %+v, want Changed=true Cleaned=CLEANED", blocks[0])
+	}
+	if blocks[1].Changed {
+		t.Errorf("blocks[1] = %+v, want Changed=false", blocks[1])
+	}
+}
+
+func TestTransformLeavesProseUntouched(t *testing.T) {
+	doc := "Just some prose.\nNo code blocks here.\n"
+
+	rewritten, blocks := Transform(doc, func(content string) (string, bool) {
+		t.Fatalf("transform should not be called with no fenced blocks, got %q", content)
+		return content, false
+	})
+
+	if rewritten != doc {
+		t.Errorf("rewritten = %q, want unchanged %q", rewritten, doc)
+	}
+	if len(blocks) != 0 {
+		t.Errorf("len(blocks) = %d, want 0", len(blocks))
+	}
+}
+
+func TestTransformFlushesUnterminatedFenceVerbatim(t *testing.T) {
+	doc := "```\nincomplete block\n"
+
+	rewritten, blocks := Transform(doc, func(content string) (string, bool) {
+		return "SHOULD NOT APPEAR", true
+	})
+
+	if rewritten != doc {
+		t.Errorf("rewritten = %q, want unchanged %q", rewritten, doc)
+	}
+	if len(blocks) != 0 {
+		t.Errorf("len(blocks) = %d, want 0 for an unterminated fence", len(blocks))
+	}
+}
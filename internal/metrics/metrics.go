@@ -0,0 +1,110 @@
+// Package metrics exposes TraceTrim's runtime cleaning statistics as Prometheus
+// metrics, either pulled via an HTTP /metrics endpoint or pushed to a Pushgateway
+// endpoint for users who run TraceTrim as a long-lived daemon.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Collector owns the counters and histograms TraceTrim reports for every clipboard
+// change it handles and every cleaning pass. Polls that find the clipboard
+// unchanged are not counted.
+type Collector struct {
+	Polls                 prometheus.Counter
+	TracesDetected        prometheus.Counter
+	FramesRemoved         prometheus.Counter
+	BytesSaved            prometheus.Counter
+	OversizeRejections    prometheus.Counter
+	InvalidUTF8Rejections prometheus.Counter
+	CleaningLatency       prometheus.Histogram
+
+	registry *prometheus.Registry
+}
+
+// NewCollector creates a Collector with all metrics registered against a fresh registry
+func NewCollector() *Collector {
+	registry := prometheus.NewRegistry()
+
+	c := &Collector{
+		Polls: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_clipboard_polls_total",
+			Help: "Total number of clipboard changes handled; polls that find the clipboard unchanged are not counted.",
+		}),
+		TracesDetected: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_stack_traces_detected_total",
+			Help: "Total number of clipboard contents recognized as stack traces.",
+		}),
+		FramesRemoved: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_frames_removed_total",
+			Help: "Total number of repetitive stack frames removed.",
+		}),
+		BytesSaved: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_bytes_saved_total",
+			Help: "Total number of bytes saved by cleaning stack traces.",
+		}),
+		OversizeRejections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_oversize_rejections_total",
+			Help: "Total number of clipboard contents rejected for exceeding the max content size.",
+		}),
+		InvalidUTF8Rejections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tracetrim_invalid_utf8_rejections_total",
+			Help: "Total number of clipboard contents rejected for containing invalid UTF-8.",
+		}),
+		CleaningLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tracetrim_cleaning_latency_seconds",
+			Help:    "Time taken to clean a detected stack trace.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		registry: registry,
+	}
+
+	registry.MustRegister(
+		c.Polls,
+		c.TracesDetected,
+		c.FramesRemoved,
+		c.BytesSaved,
+		c.OversizeRejections,
+		c.InvalidUTF8Rejections,
+		c.CleaningLatency,
+	)
+
+	return c
+}
+
+// Handler returns the HTTP handler serving the metrics in Prometheus text format
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP server exposing the metrics at path on addr, blocking until
+// ctx is cancelled or the server fails.
+func (c *Collector) Serve(ctx context.Context, addr, path string) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, c.Handler())
+
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errChan:
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("metrics server failed: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+const (
+	// pushInitialBackoff is the first retry delay after a failed push
+	pushInitialBackoff = 1 * time.Second
+
+	// pushMaxBackoff caps exponential backoff between retries
+	pushMaxBackoff = 30 * time.Second
+
+	// pushMaxRetries bounds the attempts (first try included) for a single push before giving up until the next interval
+	pushMaxRetries = 5
+)
+
+// PushConfig configures periodic Pushgateway pushing of the collector's
+// metrics, as an alternative (or addition) to the pull-based /metrics endpoint.
+type PushConfig struct {
+	// URL is the Prometheus Pushgateway endpoint
+	URL string
+
+	// Interval is how often to push
+	Interval time.Duration
+
+	// Job is the Pushgateway job label
+	Job string
+}
+
+// StartPushing pushes the collector's metrics to cfg.URL every cfg.Interval until
+// ctx is cancelled, retrying failed pushes with exponential backoff that honors any
+// Retry-After header on 5xx responses.
+func (c *Collector) StartPushing(ctx context.Context, cfg PushConfig) {
+	capture := newRetryAfterCapture(&http.Client{})
+	pusher := push.New(cfg.URL, cfg.Job).Gatherer(c.registry).Client(capture)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pushWithRetry(ctx, pusher, capture); err != nil {
+				log.Printf("metrics: giving up pushing to %s after retries: %v", cfg.URL, err)
+			}
+		}
+	}
+}
+
+// pusher is the subset of push.Pusher used here, so retry logic can be tested
+// against a fake.
+type pusher interface {
+	Push() error
+}
+
+// retryAfterSource reports the Retry-After delay carried by the most recent 5xx
+// response observed by the HTTP transport a push went through, so pushWithRetry
+// can honor it even though push.Pusher's own error has already discarded the
+// response by the time Push() returns.
+type retryAfterSource interface {
+	takeRetryAfter() (time.Duration, bool)
+}
+
+// pushWithRetry attempts a single push, retrying with exponential backoff (capped
+// at pushMaxBackoff) when the push fails. If the failure carries an HTTP 5xx status
+// with a Retry-After header, that value overrides the computed backoff.
+func pushWithRetry(ctx context.Context, p pusher, retryAfter retryAfterSource) error {
+	backoff := pushInitialBackoff
+
+	var lastErr error
+	for attempt := 0; attempt < pushMaxRetries; attempt++ {
+		lastErr = p.Push()
+		if lastErr == nil {
+			return nil
+		}
+
+		delay := backoff
+		if d, ok := retryAfter.takeRetryAfter(); ok {
+			delay = d
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		backoff *= 2
+		if backoff > pushMaxBackoff {
+			backoff = pushMaxBackoff
+		}
+	}
+
+	return fmt.Errorf("push failed after %d attempts: %w", pushMaxRetries, lastErr)
+}
+
+// retryAfterCapture wraps the push.HTTPDoer used by push.Pusher so the Retry-After
+// header on a 5xx response can be read after Push() returns. push.Pusher itself
+// only ever hands back an error built from the response's status code and body,
+// so there is no way to recover the header from that error - the transport has to
+// capture it on the way through instead.
+type retryAfterCapture struct {
+	doer interface {
+		Do(*http.Request) (*http.Response, error)
+	}
+
+	mu    sync.Mutex
+	delay time.Duration
+	ok    bool
+}
+
+func newRetryAfterCapture(doer *http.Client) *retryAfterCapture {
+	return &retryAfterCapture{doer: doer}
+}
+
+// Do satisfies push.HTTPDoer, recording any Retry-After delay from a 5xx response
+// before returning it to the caller unchanged.
+func (c *retryAfterCapture) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if resp.StatusCode >= 500 {
+		c.delay, c.ok = parseRetryAfter(resp.Header.Get("Retry-After"))
+	} else {
+		c.ok = false
+	}
+
+	return resp, err
+}
+
+// takeRetryAfter returns the Retry-After delay captured from the most recent 5xx
+// response, if any, clearing it so a stale value isn't reused by a later attempt
+// whose response carried none.
+func (c *retryAfterCapture) takeRetryAfter() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delay, ok := c.delay, c.ok
+	c.ok = false
+	return delay, ok
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, which may be either a
+// number of seconds or an HTTP-date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(header); err == nil {
+		return time.Until(when), true
+	}
+
+	return 0, false
+}
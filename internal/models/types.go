@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // ErrorInfo contains the essential information from a stack trace
 type ErrorInfo struct {
@@ -8,13 +11,23 @@ type ErrorInfo struct {
 	Message   string   // The main error message (8 bytes)
 	Source    string   // Original source file/line if available (8 bytes)
 	Component string   // React component name if applicable (8 bytes)
+	CodeFrame string   // Rendered source excerpt around Source, empty if unavailable (8 bytes)
 }
 
+// Clipboard content origins, used to distinguish external changes from TraceTrim's
+// own writes so consumers can filter write-back loops explicitly.
+const (
+	ClipboardOriginExternal = "external"
+	ClipboardOriginSelf     = "self"
+)
+
 // ClipboardContent represents clipboard data with metadata
 type ClipboardContent struct {
-	Content   string    // The actual clipboard content
+	Content   string    // The actual clipboard content, decoded to text when possible
 	Timestamp time.Time // When this content was captured
-	Format    string    // Content format (text/plain, etc.)
+	Format    string    // Content MIME type (text/plain, text/html, image/png, etc.)
+	Raw       []byte    // Raw bytes for the captured format, used for non-text formats
+	Origin    string    // Who produced this content: ClipboardOriginExternal or ClipboardOriginSelf
 }
 
 // StackFrame represents a single frame in a stack trace
@@ -23,13 +36,120 @@ type StackFrame struct {
 	File     string // File path
 	Line     int    // Line number
 	Column   int    // Column number (if available)
+
+	// CollapsedCount is how many duplicate occurrences of this frame were
+	// removed from the cleaned output (0 if it appeared only once)
+	CollapsedCount int
+
+	// IsRepeatOf is the 1-based index, within the same CleanResult.Frames, of
+	// this frame's first occurrence, or 0 if this is that first occurrence.
+	// Only set when more than one occurrence of a frame survives into Frames
+	// (e.g. the preserved first/last block of a recursion cycle) - a frame
+	// whose duplicates were fully removed has no later occurrence to point to.
+	IsRepeatOf int
+
+	// RepeatCount is how many times this frame's signature appears in the
+	// trace once recursion cycles are collapsed to their first and last
+	// repetition, 1 if it appears only once.
+	RepeatCount int
+
+	// OriginalSource is "file:line:col" pointing at this frame's pre-bundling
+	// location when a source map resolved it, empty otherwise
+	OriginalSource string
+
+	// IsNative is true when the frame names a native/engine call rather than
+	// user code (e.g. "Array.forEach (<anonymous>)" or "[native code]")
+	IsNative bool
+
+	// Raw is the frame's original, unannotated line exactly as it appeared
+	// in the input, before any "[xN]"/"// originally at ..." annotation
+	Raw string
+}
+
+// Stack trace languages recognized by the parser package.
+const (
+	LanguageUnknown    = ""
+	LanguageJavaScript = "javascript"
+	LanguageGo         = "go"
+	LanguagePython     = "python"
+	LanguageJava       = "java"
+	LanguageDotNet     = "dotnet"
+	LanguageRuby       = "ruby"
+)
+
+// CycleInfo describes one detected recursion cycle in a stack trace: a
+// contiguous run of frames that repeats as a whole (e.g. mutual recursion
+// between A and B), as opposed to a single frame simply appearing more than
+// once at unrelated points in the trace.
+type CycleInfo struct {
+	StartFrame  int // Index, among stack frame lines, of the cycle's first occurrence
+	Length      int // Number of frames in one repetition of the cycle
+	Repetitions int // How many times the cycle repeats (always >= 2)
 }
 
 // CleanResult contains the cleaned stack trace and metadata
 type CleanResult struct {
-	Frames    []StackFrame // Parsed stack frames (24 bytes - pointer + len + cap)
-	ErrorInfo *ErrorInfo   // Extracted error information (8 bytes)
-	Original  string       // Original stack trace (8 bytes)
-	Cleaned   string       // Cleaned stack trace (8 bytes)
-	Removed   int          // Number of repetitive blocks removed (8 bytes)
+	Frames      []StackFrame // Parsed stack frames (24 bytes - pointer + len + cap)
+	ErrorInfo   *ErrorInfo   // Extracted error information (8 bytes)
+	Original    string       // Original stack trace (8 bytes)
+	Cleaned     string       // Cleaned stack trace (8 bytes)
+	Language    string       // Detected stack trace language, one of the Language* constants (8 bytes)
+	Removed     int          // Number of repetitive blocks removed (8 bytes)
+	BytesSaved  int          // Difference in byte length between Original and Cleaned (8 bytes)
+	LinesBefore int          // Number of lines in Original (8 bytes)
+	LinesAfter  int          // Number of lines in Cleaned (8 bytes)
+
+	// Cycles lists recursion cycles detected among the trace's stack frame
+	// lines (see CycleInfo). The JavaScript/React cleaner collapses each one
+	// in Cleaned and Frames to its first and last repetition plus a marker
+	// comment. Only populated for the JavaScript/React dialect today.
+	Cycles []CycleInfo
+
+	// RemovedGroups lists the frame signatures Cleaned collapsed duplicates
+	// of, and how many occurrences of each were removed. Only populated for
+	// the JavaScript/React dialect today.
+	RemovedGroups []RemovedGroup
+}
+
+// RemovedGroup describes one set of duplicate or repeated frames collapsed
+// out of CleanResult.Cleaned.
+type RemovedGroup struct {
+	// Frame names the repeated frame: its function name, or for a multi-frame
+	// recursion cycle, each frame in one repetition joined with " -> ".
+	Frame string
+
+	// Count is how many occurrences of Frame were removed from Original.
+	Count int
+}
+
+// cleanResultJSON is the wire shape of CleanResult.MarshalJSON.
+type cleanResultJSON struct {
+	Error         *ErrorInfo       `json:"error,omitempty"`
+	Frames        []StackFrame     `json:"frames"`
+	RemovedGroups []RemovedGroup   `json:"removedGroups"`
+	Stats         cleanResultStats `json:"stats"`
+}
+
+type cleanResultStats struct {
+	Removed     int `json:"removed"`
+	BytesSaved  int `json:"bytesSaved"`
+	LinesBefore int `json:"linesBefore"`
+	LinesAfter  int `json:"linesAfter"`
+}
+
+// MarshalJSON encodes r as {error, frames[], removedGroups[], stats{}} for
+// consumers (CI annotators, LSP diagnostics, Slackbots) that want structured
+// output instead of re-parsing Cleaned.
+func (r CleanResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cleanResultJSON{
+		Error:         r.ErrorInfo,
+		Frames:        r.Frames,
+		RemovedGroups: r.RemovedGroups,
+		Stats: cleanResultStats{
+			Removed:     r.Removed,
+			BytesSaved:  r.BytesSaved,
+			LinesBefore: r.LinesBefore,
+			LinesAfter:  r.LinesAfter,
+		},
+	})
 }
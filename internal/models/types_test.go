@@ -198,9 +198,11 @@ func TestModelFieldAlignment(t *testing.T) {
 	original := "line1\nline2\nline3"
 	cleaned := "line1\nline3"
 	cleanResult := CleanResult{
-		Original:   original,
-		Cleaned:    cleaned,
-		BytesSaved: len(original) - len(cleaned), // Calculate actual difference
+		Original:    original,
+		Cleaned:     cleaned,
+		BytesSaved:  len(original) - len(cleaned), // Calculate actual difference
+		LinesBefore: strings.Count(original, "\n") + 1,
+		LinesAfter:  strings.Count(cleaned, "\n") + 1,
 	}
 
 	if cleanResult.BytesSaved != len(cleanResult.Original)-len(cleanResult.Cleaned) {
@@ -0,0 +1,77 @@
+// Package codeframe renders a short excerpt of source code around an error
+// position, with a caret pointing at the offending column, mirroring
+// Vitest's "display error frame if present" behavior.
+package codeframe
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ANSI escapes used to dim the gutter and highlight the offending line and
+// caret. EnableColor can be turned off when output is being piped.
+const (
+	colorDim   = "\x1b[2m"
+	colorRed   = "\x1b[31m"
+	colorReset = "\x1b[0m"
+)
+
+// EnableColor controls whether Generate wraps its output in ANSI color
+// codes. Callers piping output to a file or another process should disable
+// this first.
+var EnableColor = true
+
+// Generate renders contextLines of source on either side of line, with a
+// "^" caret under col on the error line itself. line and col are 1-based,
+// matching stack frame conventions. Returns "" if line falls outside
+// sourceCode.
+func Generate(sourceCode string, line, col, contextLines int) string {
+	lines := strings.Split(sourceCode, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	start := line - contextLines
+	if start < 1 {
+		start = 1
+	}
+	end := line + contextLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	gutterWidth := len(fmt.Sprintf("%d", end))
+
+	var b strings.Builder
+	for lineNo := start; lineNo <= end; lineNo++ {
+		text := lines[lineNo-1]
+		marker := "  "
+		if lineNo == line {
+			marker = "> "
+		}
+
+		gutter := fmt.Sprintf("%s%*d |", marker, gutterWidth, lineNo)
+		if EnableColor && lineNo == line {
+			gutter = colorRed + gutter + colorReset
+		} else if EnableColor {
+			gutter = colorDim + gutter + colorReset
+		}
+
+		fmt.Fprintf(&b, "%s %s\n", gutter, text)
+
+		if lineNo == line {
+			caretCol := col
+			if caretCol < 1 {
+				caretCol = 1
+			}
+			padding := strings.Repeat(" ", len(marker)+gutterWidth+3+caretCol-1)
+			caret := "^"
+			if EnableColor {
+				caret = colorRed + caret + colorReset
+			}
+			fmt.Fprintf(&b, "%s%s\n", padding, caret)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,49 @@
+package codeframe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateMarksErrorLineAndColumn(t *testing.T) {
+	EnableColor = false
+	defer func() { EnableColor = true }()
+
+	source := "function foo() {\n  return bar.baz();\n}\n"
+
+	frame := Generate(source, 2, 10, 1)
+
+	lines := strings.Split(frame, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4 (3 source lines + 1 caret):\n%s", len(lines), frame)
+	}
+	if !strings.Contains(lines[1], "return bar.baz();") {
+		t.Errorf("line 2 = %q, want the error line", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], "^") {
+		t.Errorf("caret line = %q, want it to end in \"^\"", lines[2])
+	}
+}
+
+func TestGenerateReturnsEmptyForOutOfRangeLine(t *testing.T) {
+	if got := Generate("a\nb\n", 99, 1, 2); got != "" {
+		t.Errorf("Generate() = %q, want empty string for an out-of-range line", got)
+	}
+}
+
+func TestGenerateRespectsColorToggle(t *testing.T) {
+	source := "a\nb\nc\n"
+
+	EnableColor = true
+	colored := Generate(source, 2, 1, 0)
+	if !strings.Contains(colored, "\x1b[") {
+		t.Error("expected ANSI escape codes when EnableColor is true")
+	}
+
+	EnableColor = false
+	plain := Generate(source, 2, 1, 0)
+	if strings.Contains(plain, "\x1b[") {
+		t.Error("expected no ANSI escape codes when EnableColor is false")
+	}
+	EnableColor = true
+}
@@ -0,0 +1,337 @@
+// Package report renders a script-mode cleaning result as plain text, JSON,
+// JUnit XML, or SARIF, so CI pipelines can consume TraceTrim's output
+// programmatically instead of scraping STDOUT.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Supported script-mode output formats.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatSarif = "sarif"
+)
+
+// ErrorInfo is the subset of models.ErrorInfo a report cares about. It is
+// duplicated here rather than imported so this package stays decoupled from
+// internal/models and can be reused by anything that can produce these
+// fields (script mode today, a future batch-processing mode tomorrow).
+type ErrorInfo struct {
+	Message   string
+	Source    string
+	Component string
+	CodeFrame string
+}
+
+// Frame is the subset of models.StackFrame a report cares about, duplicated
+// here for the same reason as ErrorInfo above.
+type Frame struct {
+	Function       string
+	File           string
+	Line           int
+	Column         int
+	CollapsedCount int
+	OriginalSource string
+	IsNative       bool
+	Raw            string
+}
+
+// Result is everything an encoder needs to render one script-mode
+// invocation's outcome.
+type Result struct {
+	// Detected is false when no stack trace was found in the input; in that
+	// case Original and Cleaned both hold the input passed through verbatim
+	// and the other fields are zero.
+	Detected bool
+
+	// StackType is a human label such as "React", "JavaScript", "Go", or
+	// "Python". Empty when Detected is false.
+	StackType string
+
+	ErrorInfo *ErrorInfo
+
+	// Frames is the structured per-frame breakdown of Cleaned, used by
+	// FormatJSON and FormatSarif. May be empty even when Detected is true,
+	// for dialects that don't populate models.CleanResult.Frames yet.
+	Frames []Frame
+
+	Original string
+	Cleaned  string
+
+	FramesRemoved int
+	BytesSaved    int
+}
+
+// PercentSaved returns the percentage of bytes removed relative to Original,
+// or 0 if Original is empty.
+func (r Result) PercentSaved() float64 {
+	if len(r.Original) == 0 {
+		return 0
+	}
+	return float64(r.BytesSaved) / float64(len(r.Original)) * 100
+}
+
+// Encode writes result to w in the given format. An unrecognized format is a
+// caller bug, not a runtime condition, so it is reported as an error rather
+// than silently falling back to text.
+func Encode(w io.Writer, format string, result Result) error {
+	switch format {
+	case FormatText, "":
+		return encodeText(w, result)
+	case FormatJSON:
+		return encodeJSON(w, result)
+	case FormatJUnit:
+		return encodeJUnit(w, result)
+	case FormatSarif:
+		return encodeSarif(w, result)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// encodeText reproduces script mode's original behavior: the cleaned (or,
+// if nothing was detected, the verbatim) content and nothing else.
+func encodeText(w io.Writer, result Result) error {
+	_, err := io.WriteString(w, result.Cleaned)
+	return err
+}
+
+// jsonFrame is the wire shape of a single Frame within FormatJSON output.
+type jsonFrame struct {
+	Function       string `json:"function"`
+	File           string `json:"file"`
+	Line           int    `json:"line"`
+	Col            int    `json:"col"`
+	CollapsedCount int    `json:"collapsedCount,omitempty"`
+	OriginalSource string `json:"originalSource,omitempty"`
+	IsNative       bool   `json:"isNative,omitempty"`
+	Raw            string `json:"raw,omitempty"`
+}
+
+// jsonResult is the wire shape of FormatJSON output.
+type jsonResult struct {
+	StackType     string      `json:"stack_type,omitempty"`
+	ErrorInfo     *ErrorInfo  `json:"error_info,omitempty"`
+	Frames        []jsonFrame `json:"frames,omitempty"`
+	Cleaned       string      `json:"cleaned"`
+	OriginalBytes int         `json:"original_bytes"`
+	CleanedBytes  int         `json:"cleaned_bytes"`
+	BytesSaved    int         `json:"bytes_saved"`
+	PercentSaved  float64     `json:"percent_saved"`
+	FramesRemoved int         `json:"frames_removed"`
+}
+
+func toJSONFrames(frames []Frame) []jsonFrame {
+	if len(frames) == 0 {
+		return nil
+	}
+	out := make([]jsonFrame, len(frames))
+	for i, f := range frames {
+		out[i] = jsonFrame{
+			Function:       f.Function,
+			File:           f.File,
+			Line:           f.Line,
+			Col:            f.Column,
+			CollapsedCount: f.CollapsedCount,
+			OriginalSource: f.OriginalSource,
+			IsNative:       f.IsNative,
+			Raw:            f.Raw,
+		}
+	}
+	return out
+}
+
+func encodeJSON(w io.Writer, result Result) error {
+	out := jsonResult{
+		StackType:     result.StackType,
+		ErrorInfo:     result.ErrorInfo,
+		Frames:        toJSONFrames(result.Frames),
+		Cleaned:       result.Cleaned,
+		OriginalBytes: len(result.Original),
+		CleanedBytes:  len(result.Cleaned),
+		BytesSaved:    result.BytesSaved,
+		PercentSaved:  result.PercentSaved(),
+		FramesRemoved: result.FramesRemoved,
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(out)
+}
+
+// junitTestSuite and junitTestCase mirror the subset of the JUnit XML schema
+// that report dashboards (including Ginkgo's --junit-report consumers)
+// expect: a single suite wrapping one case for the input, which fails when
+// no stack trace was detected.
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func encodeJUnit(w io.Writer, result Result) error {
+	tc := junitTestCase{
+		Name:      "stack-trace-1",
+		ClassName: "tracetrim.clean",
+	}
+
+	suite := junitTestSuite{
+		Name:  "tracetrim",
+		Tests: 1,
+	}
+
+	if !result.Detected {
+		suite.Failures = 1
+		tc.Failure = &junitFailure{
+			Message: "no stack trace detected in input",
+			Content: result.Original,
+		}
+	} else {
+		tc.SystemOut = result.Cleaned
+	}
+
+	suite.Cases = []junitTestCase{tc}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// SARIF (Static Analysis Results Interchange Format) 2.1.0 is the format
+// most code-scanning dashboards (including GitHub's) expect, so a tool that
+// wants one of its findings surfaced alongside lint/security results there
+// needs to emit it. The structures below cover only the subset of the spec
+// TraceTrim needs: one run, one rule, one result per stack frame.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+const sarifRuleIDStackFrame = "tracetrim/stack-frame"
+
+func encodeSarif(w io.Writer, result Result) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name: "TraceTrim",
+				Rules: []sarifRule{
+					{ID: sarifRuleIDStackFrame, Name: "StackFrame"},
+				},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	for _, f := range result.Frames {
+		if f.File == "" {
+			continue
+		}
+		text := f.Function
+		if text == "" {
+			text = "(anonymous)"
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID: sarifRuleIDStackFrame,
+			Level:  "warning",
+			Message: sarifMessage{
+				Text: fmt.Sprintf("stack frame in %s", text),
+			},
+			Locations: []sarifLocation{
+				{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: f.File},
+						Region: sarifRegion{
+							StartLine:   f.Line,
+							StartColumn: f.Column,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(log)
+}
@@ -0,0 +1,178 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTextWritesCleanedContentOnly(t *testing.T) {
+	var buf strings.Builder
+	result := Result{Detected: true, Cleaned: "cleaned trace"}
+
+	if err := Encode(&buf, FormatText, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if buf.String() != "cleaned trace" {
+		t.Errorf("output = %q, want %q", buf.String(), "cleaned trace")
+	}
+}
+
+func TestEncodeJSONIncludesExpectedFields(t *testing.T) {
+	var buf strings.Builder
+	result := Result{
+		Detected:      true,
+		StackType:     "React",
+		Original:      "0123456789",
+		Cleaned:       "01234",
+		FramesRemoved: 3,
+		BytesSaved:    5,
+	}
+
+	if err := Encode(&buf, FormatJSON, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	var out jsonResult
+	if err := json.Unmarshal([]byte(buf.String()), &out); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if out.StackType != "React" {
+		t.Errorf("StackType = %q, want %q", out.StackType, "React")
+	}
+	if out.OriginalBytes != 10 || out.CleanedBytes != 5 {
+		t.Errorf("OriginalBytes/CleanedBytes = %d/%d, want 10/5", out.OriginalBytes, out.CleanedBytes)
+	}
+	if out.PercentSaved != 50 {
+		t.Errorf("PercentSaved = %v, want 50", out.PercentSaved)
+	}
+	if out.FramesRemoved != 3 {
+		t.Errorf("FramesRemoved = %d, want 3", out.FramesRemoved)
+	}
+}
+
+func TestEncodeJUnitMarksUndetectedTraceAsFailure(t *testing.T) {
+	var buf strings.Builder
+	result := Result{Detected: false, Original: "plain text, not a trace"}
+
+	if err := Encode(&buf, FormatJUnit, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal([]byte(buf.String()), &suite); err != nil {
+		t.Fatalf("failed to parse JUnit output: %v", err)
+	}
+
+	if suite.Failures != 1 {
+		t.Errorf("Failures = %d, want 1", suite.Failures)
+	}
+	if len(suite.Cases) != 1 || suite.Cases[0].Failure == nil {
+		t.Fatalf("expected exactly one failing testcase, got: %+v", suite.Cases)
+	}
+	if suite.Cases[0].Failure.Content != result.Original {
+		t.Errorf("failure content = %q, want %q", suite.Cases[0].Failure.Content, result.Original)
+	}
+}
+
+func TestEncodeUnknownFormatReturnsError(t *testing.T) {
+	var buf strings.Builder
+	if err := Encode(&buf, "xml", Result{}); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
+
+func TestEncodeJSONIncludesFrames(t *testing.T) {
+	var buf strings.Builder
+	result := Result{
+		Detected: true,
+		Cleaned:  "cleaned trace",
+		Frames: []Frame{
+			{Function: "foo", File: "foo.js", Line: 10, Column: 5, CollapsedCount: 2, OriginalSource: "foo.ts:3:1", IsNative: true, Raw: "    at foo (foo.js:10:5)"},
+		},
+	}
+
+	if err := Encode(&buf, FormatJSON, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	var out jsonResult
+	if err := json.Unmarshal([]byte(buf.String()), &out); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if len(out.Frames) != 1 {
+		t.Fatalf("Frames = %+v, want 1 entry", out.Frames)
+	}
+	frame := out.Frames[0]
+	if frame.Function != "foo" || frame.File != "foo.js" || frame.Line != 10 || frame.Col != 5 {
+		t.Errorf("frame = %+v, want function=foo file=foo.js line=10 col=5", frame)
+	}
+	if frame.CollapsedCount != 2 || frame.OriginalSource != "foo.ts:3:1" {
+		t.Errorf("frame = %+v, want collapsedCount=2 originalSource=foo.ts:3:1", frame)
+	}
+	if !frame.IsNative || frame.Raw != "    at foo (foo.js:10:5)" {
+		t.Errorf("frame = %+v, want isNative=true raw=%q", frame, "    at foo (foo.js:10:5)")
+	}
+}
+
+func TestEncodeSarifProducesOneResultPerFrame(t *testing.T) {
+	var buf strings.Builder
+	result := Result{
+		Detected: true,
+		Cleaned:  "cleaned trace",
+		Frames: []Frame{
+			{Function: "foo", File: "foo.js", Line: 10, Column: 5},
+			{Function: "", File: "bar.js", Line: 1, Column: 1},
+		},
+	}
+
+	if err := Encode(&buf, FormatSarif, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	var out sarifLog
+	if err := json.Unmarshal([]byte(buf.String()), &out); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+
+	if out.Version != "2.1.0" {
+		t.Errorf("Version = %q, want 2.1.0", out.Version)
+	}
+	if len(out.Runs) != 1 {
+		t.Fatalf("Runs = %+v, want 1 entry", out.Runs)
+	}
+	run := out.Runs[0]
+	if len(run.Results) != 2 {
+		t.Fatalf("Results = %+v, want 2 entries", run.Results)
+	}
+	if run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI != "foo.js" {
+		t.Errorf("URI = %q, want foo.js", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+	if run.Results[1].Message.Text != "stack frame in (anonymous)" {
+		t.Errorf("Message.Text = %q, want anonymous fallback", run.Results[1].Message.Text)
+	}
+}
+
+func TestEncodeSarifSkipsFramesWithoutFile(t *testing.T) {
+	var buf strings.Builder
+	result := Result{
+		Detected: true,
+		Frames:   []Frame{{Function: "foo"}},
+	}
+
+	if err := Encode(&buf, FormatSarif, result); err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	var out sarifLog
+	if err := json.Unmarshal([]byte(buf.String()), &out); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+
+	if len(out.Runs[0].Results) != 0 {
+		t.Errorf("Results = %+v, want 0 entries for a frame with no file", out.Runs[0].Results)
+	}
+}
@@ -0,0 +1,277 @@
+// Package sourcemap resolves a minified/bundled JS or TS stack frame back to its
+// original source position using an adjacent Source Map v3 (".map") file, similar
+// to how Vitest's error printer uses source maps to point users at the real file.
+package sourcemap
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fetchTimeout bounds how long a network fetch of a ".map" file may take.
+const fetchTimeout = 5 * time.Second
+
+// Map is a decoded Source Map v3 document: the list of original source files it
+// references, plus every generated->original position mapping, sorted by
+// generated position so Original can binary-search it.
+type Map struct {
+	Sources  []string
+	segments []segment
+}
+
+// segment is one decoded "mappings" entry. Fields are absolute, already-resolved
+// positions (the VLQ deltas have been applied); hasSource is false for segments
+// that only map a generated position with no corresponding original location.
+type segment struct {
+	genLine, genCol int
+	srcIndex        int
+	srcLine, srcCol int
+	hasSource       bool
+}
+
+// rawSourceMap is the subset of the Source Map v3 schema this package uses.
+type rawSourceMap struct {
+	Version  int      `json:"version"`
+	Sources  []string `json:"sources"`
+	Mappings string   `json:"mappings"`
+}
+
+// ParseMap decodes a Source Map v3 JSON document.
+func ParseMap(data []byte) (*Map, error) {
+	var raw rawSourceMap
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse source map: %w", err)
+	}
+
+	segments, err := decodeMappings(raw.Mappings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode source map mappings: %w", err)
+	}
+
+	return &Map{Sources: raw.Sources, segments: segments}, nil
+}
+
+// Original returns the original source file, line, and column (both 1-based, to
+// match stack frame conventions) for a generated position, or ok=false if the map
+// has no mapping at or before that position.
+func (m *Map) Original(genLine, genCol int) (file string, line int, col int, ok bool) {
+	targetLine := genLine - 1
+	targetCol := genCol - 1
+	if targetLine < 0 {
+		targetLine = 0
+	}
+	if targetCol < 0 {
+		targetCol = 0
+	}
+
+	// segments is sorted by (genLine, genCol); find the first segment strictly
+	// after the target and walk back to the nearest preceding mapping.
+	idx := sort.Search(len(m.segments), func(i int) bool {
+		s := m.segments[i]
+		return s.genLine > targetLine || (s.genLine == targetLine && s.genCol > targetCol)
+	})
+
+	for i := idx - 1; i >= 0; i-- {
+		s := m.segments[i]
+		if !s.hasSource {
+			continue
+		}
+		if s.srcIndex < 0 || s.srcIndex >= len(m.Sources) {
+			return "", 0, 0, false
+		}
+		return m.Sources[s.srcIndex], s.srcLine + 1, s.srcCol + 1, true
+	}
+
+	return "", 0, 0, false
+}
+
+// Resolver looks up and decodes ".map" files for generated frames, caching
+// decoded maps by content hash so repeated frames from the same bundle don't
+// re-decode its mappings.
+type Resolver struct {
+	mu           sync.Mutex
+	cache        map[string]*Map
+	searchRoot   string
+	allowNetwork bool
+	httpClient   *http.Client
+}
+
+// NewResolver builds a Resolver. searchRoot is the directory local ".map" files
+// are resolved relative to when the referenced file isn't an absolute path
+// (empty means resolve alongside the referenced file). allowNetwork permits
+// fetching ".map" files over http(s) for frames that reference a URL.
+func NewResolver(searchRoot string, allowNetwork bool) *Resolver {
+	return &Resolver{
+		cache:        make(map[string]*Map),
+		searchRoot:   searchRoot,
+		allowNetwork: allowNetwork,
+		httpClient:   &http.Client{Timeout: fetchTimeout},
+	}
+}
+
+// Resolve maps a generated frame's file/line/column back to its original source
+// position, returning ok=false if no map could be found or decoded for file.
+func (r *Resolver) Resolve(file string, line, col int) (origFile string, origLine, origCol int, ok bool) {
+	data, found := r.loadMapData(file)
+	if !found {
+		return "", 0, 0, false
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	r.mu.Lock()
+	m, cached := r.cache[hash]
+	r.mu.Unlock()
+
+	if !cached {
+		parsed, err := ParseMap(data)
+		if err != nil {
+			return "", 0, 0, false
+		}
+		m = parsed
+		r.mu.Lock()
+		r.cache[hash] = m
+		r.mu.Unlock()
+	}
+
+	return m.Original(line, col)
+}
+
+// loadMapData reads the ".map" file adjacent to file, from the network if file
+// is a URL and network fetches are allowed, or from disk otherwise.
+func (r *Resolver) loadMapData(file string) ([]byte, bool) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		if !r.allowNetwork {
+			return nil, false
+		}
+		return r.fetchMapData(file + ".map")
+	}
+
+	path := file + ".map"
+	if r.searchRoot != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(r.searchRoot, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (r *Resolver) fetchMapData(url string) ([]byte, bool) {
+	resp, err := r.httpClient.Get(url) //nolint:gosec // url is derived from a stack frame's own source file reference
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only response body
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// base64VLQChars is the Source Map v3 base64 alphabet.
+const base64VLQChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+var base64VLQDecodeTable = buildVLQDecodeTable()
+
+func buildVLQDecodeTable() [128]int8 {
+	var table [128]int8
+	for i := range table {
+		table[i] = -1
+	}
+	for i, c := range base64VLQChars {
+		table[c] = int8(i)
+	}
+	return table
+}
+
+// decodeVLQSegment decodes one comma-separated "mappings" segment into its
+// sequence of signed, delta-encoded fields (Source Map v3's base64 VLQ format).
+func decodeVLQSegment(segment string) ([]int, error) {
+	var fields []int
+	shift, value := uint(0), 0
+
+	for _, c := range segment {
+		if c >= 128 || base64VLQDecodeTable[c] == -1 {
+			return nil, fmt.Errorf("invalid base64 VLQ character %q", c)
+		}
+		digit := int(base64VLQDecodeTable[c])
+
+		continuation := digit & 32
+		value += (digit & 31) << shift
+		if continuation != 0 {
+			shift += 5
+			continue
+		}
+
+		if value&1 != 0 {
+			value = -(value >> 1)
+		} else {
+			value >>= 1
+		}
+		fields = append(fields, value)
+		value, shift = 0, 0
+	}
+
+	return fields, nil
+}
+
+// decodeMappings decodes a Source Map v3 "mappings" string into a flat,
+// generated-position-sorted list of segments.
+func decodeMappings(mappings string) ([]segment, error) {
+	var segments []segment
+	genLine := 0
+	srcIndex, srcLine, srcCol := 0, 0, 0
+
+	for _, lineStr := range strings.Split(mappings, ";") {
+		genCol := 0
+		if lineStr != "" {
+			for _, raw := range strings.Split(lineStr, ",") {
+				if raw == "" {
+					continue
+				}
+				fields, err := decodeVLQSegment(raw)
+				if err != nil {
+					return nil, err
+				}
+				if len(fields) == 0 {
+					continue
+				}
+
+				genCol += fields[0]
+				s := segment{genLine: genLine, genCol: genCol}
+
+				if len(fields) >= 4 {
+					srcIndex += fields[1]
+					srcLine += fields[2]
+					srcCol += fields[3]
+					s.srcIndex, s.srcLine, s.srcCol, s.hasSource = srcIndex, srcLine, srcCol, true
+				}
+
+				segments = append(segments, s)
+			}
+		}
+		genLine++
+	}
+
+	return segments, nil
+}
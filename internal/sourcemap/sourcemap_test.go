@@ -0,0 +1,78 @@
+package sourcemap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMapAndOriginalResolvesGeneratedPosition(t *testing.T) {
+	// One generated line with a single segment: genCol=0, source 0, srcLine=0, srcCol=0 ("AAAA").
+	raw := []byte(`{"version":3,"sources":["src/App.tsx"],"mappings":"AAAA"}`)
+
+	m, err := ParseMap(raw)
+	if err != nil {
+		t.Fatalf("ParseMap returned error: %v", err)
+	}
+
+	file, line, col, ok := m.Original(1, 1)
+	if !ok {
+		t.Fatal("expected Original to find a mapping")
+	}
+	if file != "src/App.tsx" || line != 1 || col != 1 {
+		t.Errorf("Original() = (%q, %d, %d), want (%q, 1, 1)", file, line, col, "src/App.tsx")
+	}
+}
+
+func TestParseMapOriginalFallsBackToNearestPrecedingSegment(t *testing.T) {
+	// Two segments on the generated line: col 0 -> a.ts line 0, col 10 -> b.ts line 0.
+	raw := []byte(`{"version":3,"sources":["a.ts","b.ts"],"mappings":"AAAA,UCAA"}`)
+
+	m, err := ParseMap(raw)
+	if err != nil {
+		t.Fatalf("ParseMap returned error: %v", err)
+	}
+
+	file, line, _, ok := m.Original(1, 6)
+	if !ok {
+		t.Fatal("expected Original to find a mapping")
+	}
+	if file != "a.ts" || line != 1 {
+		t.Errorf("Original(1, 6) = (%q, %d), want (%q, 1)", file, line, "a.ts")
+	}
+}
+
+func TestParseMapRejectsInvalidVLQ(t *testing.T) {
+	raw := []byte(`{"version":3,"sources":["a.ts"],"mappings":"****"}`)
+
+	if _, err := ParseMap(raw); err == nil {
+		t.Error("expected an error for invalid base64 VLQ characters")
+	}
+}
+
+func TestResolverResolveReadsAdjacentMapFile(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "bundle.js")
+	mapPath := bundlePath + ".map"
+
+	if err := os.WriteFile(mapPath, []byte(`{"version":3,"sources":["src/App.tsx"],"mappings":"AAAA"}`), 0o644); err != nil {
+		t.Fatalf("failed to write fixture map: %v", err)
+	}
+
+	resolver := NewResolver("", false)
+	file, line, col, ok := resolver.Resolve(bundlePath, 1, 1)
+	if !ok {
+		t.Fatal("expected Resolve to find the adjacent map file")
+	}
+	if file != "src/App.tsx" || line != 1 || col != 1 {
+		t.Errorf("Resolve() = (%q, %d, %d), want (%q, 1, 1)", file, line, col, "src/App.tsx")
+	}
+}
+
+func TestResolverResolveMissingMapFile(t *testing.T) {
+	resolver := NewResolver("", false)
+
+	if _, _, _, ok := resolver.Resolve(filepath.Join(t.TempDir(), "missing.js"), 1, 1); ok {
+		t.Error("expected Resolve to fail when no .map file exists")
+	}
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"strings"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
+)
+
+// Dialect recognizes and cleans one stack-trace format. Third parties can add
+// support for additional languages without touching this package by calling
+// RegisterDialect from an init() in a sidecar build.
+type Dialect interface {
+	// Name is the dialect's identifier, as used by --dialect and the registry.
+	Name() string
+
+	// Detect reports whether content looks like this dialect's stack trace format.
+	Detect(content string) bool
+
+	// Clean returns the cleaned content and the number of frames/blocks collapsed.
+	Clean(content string) CleanResultPair
+}
+
+// dialectRegistry holds every registered Dialect, in registration order so
+// DetectDialect can try more specific formats (e.g. react) before more
+// general ones they could be confused with (e.g. js).
+var dialectRegistry = struct {
+	order  []string
+	byName map[string]Dialect
+}{byName: map[string]Dialect{}}
+
+// RegisterDialect adds (or replaces) a dialect in the registry.
+func RegisterDialect(d Dialect) {
+	if _, exists := dialectRegistry.byName[d.Name()]; !exists {
+		dialectRegistry.order = append(dialectRegistry.order, d.Name())
+	}
+	dialectRegistry.byName[d.Name()] = d
+}
+
+// Dialects returns every registered dialect name, in registration order.
+func Dialects() []string {
+	return append([]string(nil), dialectRegistry.order...)
+}
+
+// LookupDialect returns the dialect registered under name, if any.
+func LookupDialect(name string) (Dialect, bool) {
+	d, ok := dialectRegistry.byName[name]
+	return d, ok
+}
+
+// disabledDialects tracks dialects temporarily excluded from automatic detection
+// (via IsStackTrace/DetectLanguage/DetectDialect), configured at startup from
+// ParserConfig.DisabledDialects. An explicit --dialect selection via LookupDialect
+// is unaffected, since that's a deliberate override rather than auto-detection.
+var disabledDialects = map[string]bool{}
+
+// SetDisabledDialects replaces the set of dialects DetectDialect skips during
+// automatic detection.
+func SetDisabledDialects(names []string) {
+	disabledDialects = make(map[string]bool, len(names))
+	for _, name := range names {
+		disabledDialects[name] = true
+	}
+}
+
+// DetectDialect returns the first registered, non-disabled dialect (in registration
+// order) whose Detect reports a match for content.
+func DetectDialect(content string) (Dialect, bool) {
+	for _, name := range dialectRegistry.order {
+		if disabledDialects[name] {
+			continue
+		}
+		d := dialectRegistry.byName[name]
+		if d.Detect(content) {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// LanguageForDialect maps a dialect name to the models.Language* constant used to
+// label detection results; "react" and "js" both label as models.LanguageJavaScript
+// since react is a display-layer distinction, not a separate Language value.
+func LanguageForDialect(name string) string {
+	switch name {
+	case "go":
+		return models.LanguageGo
+	case "python":
+		return models.LanguagePython
+	case "java":
+		return models.LanguageJava
+	case "dotnet":
+		return models.LanguageDotNet
+	case "ruby":
+		return models.LanguageRuby
+	case "react", "js":
+		return models.LanguageJavaScript
+	default:
+		return models.LanguageUnknown
+	}
+}
+
+func init() {
+	// Go, Python, Java, .NET, and Ruby are registered before react/js since their
+	// headers (or, for Ruby, its "file.rb:N:in `method'" shape) are distinctive;
+	// js's frame pattern is the most permissive and must be tried last so it
+	// doesn't shadow the others.
+	RegisterDialect(goDialect{})
+	RegisterDialect(pythonDialect{})
+	RegisterDialect(javaDialect{})
+	RegisterDialect(dotnetDialect{})
+	RegisterDialect(rubyDialect{})
+	RegisterDialect(reactDialect{})
+	RegisterDialect(jsDialect{})
+}
+
+type goDialect struct{}
+
+func (goDialect) Name() string                         { return "go" }
+func (goDialect) Detect(content string) bool           { return isGoStackTrace(content) }
+func (goDialect) Clean(content string) CleanResultPair { return cleanGoStackTrace(content) }
+
+type pythonDialect struct{}
+
+func (pythonDialect) Name() string                         { return "python" }
+func (pythonDialect) Detect(content string) bool           { return isPythonStackTrace(content) }
+func (pythonDialect) Clean(content string) CleanResultPair { return cleanPythonStackTrace(content) }
+
+type javaDialect struct{}
+
+func (javaDialect) Name() string                         { return "java" }
+func (javaDialect) Detect(content string) bool           { return isJavaStackTrace(content) }
+func (javaDialect) Clean(content string) CleanResultPair { return cleanJavaStackTrace(content) }
+
+type dotnetDialect struct{}
+
+func (dotnetDialect) Name() string                         { return "dotnet" }
+func (dotnetDialect) Detect(content string) bool           { return isDotNetStackTrace(content) }
+func (dotnetDialect) Clean(content string) CleanResultPair { return cleanDotNetStackTrace(content) }
+
+type rubyDialect struct{}
+
+func (rubyDialect) Name() string                         { return "ruby" }
+func (rubyDialect) Detect(content string) bool           { return isRubyStackTrace(content) }
+func (rubyDialect) Clean(content string) CleanResultPair { return cleanRubyStackTrace(content) }
+
+type reactDialect struct{}
+
+func (reactDialect) Name() string                         { return "react" }
+func (reactDialect) Detect(content string) bool           { return isReactStackTrace(content) }
+func (reactDialect) Clean(content string) CleanResultPair { return cleanJavaScriptStackTrace(content) }
+
+type jsDialect struct{}
+
+func (jsDialect) Name() string                         { return "js" }
+func (jsDialect) Detect(content string) bool           { return isJavaScriptStackTrace(content) }
+func (jsDialect) Clean(content string) CleanResultPair { return cleanJavaScriptStackTrace(content) }
+
+// isReactStackTrace reports whether content is a JavaScript stack trace with
+// React-specific markers (component names, JSX/TSX sources, React DOM
+// internals). This mirrors the content heuristic in cmd/main.go's
+// getStackTraceType.
+func isReactStackTrace(content string) bool {
+	if !isJavaScriptStackTrace(content) {
+		return false
+	}
+
+	lower := strings.ToLower(content)
+	return strings.Contains(lower, "react") ||
+		strings.Contains(lower, "component") ||
+		strings.Contains(lower, "jsx") ||
+		strings.Contains(lower, "tsx")
+}
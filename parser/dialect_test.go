@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
+)
+
+func TestDetectDialectPrefersReactOverPlainJS(t *testing.T) {
+	reactTrace := "TypeError: Cannot read property 'map' of undefined\n" +
+		"    at Component.render (app.jsx:15:20)\n" +
+		"    at Component.render (app.jsx:15:20)"
+
+	dialect, ok := DetectDialect(reactTrace)
+	if !ok {
+		t.Fatal("expected a dialect match")
+	}
+	if dialect.Name() != "react" {
+		t.Errorf("Name() = %q, want %q", dialect.Name(), "react")
+	}
+}
+
+func TestDetectDialectRecognizesJava(t *testing.T) {
+	javaTrace := "Exception in thread \"main\" java.lang.NullPointerException: boom\n" +
+		"\tat com.example.Foo.bar(Foo.java:42)\n" +
+		"\tat com.example.Main.main(Main.java:5)"
+
+	dialect, ok := DetectDialect(javaTrace)
+	if !ok {
+		t.Fatal("expected a dialect match")
+	}
+	if dialect.Name() != "java" {
+		t.Errorf("Name() = %q, want %q", dialect.Name(), "java")
+	}
+	if got := DetectLanguage(javaTrace); got != models.LanguageJava {
+		t.Errorf("DetectLanguage() = %q, want %q", got, models.LanguageJava)
+	}
+}
+
+func TestCleanJavaStackTraceCollapsesRecursionBurst(t *testing.T) {
+	frame := "\tat com.example.Foo.recurse(Foo.java:10)"
+	var lines []string
+	lines = append(lines, "Exception in thread \"main\" java.lang.StackOverflowError")
+	for i := 0; i < 6; i++ {
+		lines = append(lines, frame)
+	}
+	trace := strings.Join(lines, "\n")
+
+	result := cleanJavaStackTrace(trace)
+
+	if result.Removed == 0 {
+		t.Error("expected some frames to be collapsed")
+	}
+	if !strings.Contains(result.Content, "more identical frame") {
+		t.Errorf("expected a collapse annotation, got: %s", result.Content)
+	}
+}
+
+func TestLookupDialectReturnsRegisteredDialects(t *testing.T) {
+	for _, name := range []string{"go", "python", "java", "dotnet", "ruby", "react", "js"} {
+		if _, ok := LookupDialect(name); !ok {
+			t.Errorf("LookupDialect(%q) not found", name)
+		}
+	}
+	if _, ok := LookupDialect("kotlin"); ok {
+		t.Error("expected LookupDialect(\"kotlin\") to be absent")
+	}
+}
+
+func TestDetectDialectRecognizesDotNetAndRuby(t *testing.T) {
+	dotnetTrace := "System.NullReferenceException: Object reference not set to an instance of an object.\n" +
+		"   at MyApp.Widget.Render() in /src/Widget.cs:line 42\n" +
+		"   at MyApp.Program.Main() in /src/Program.cs:line 10"
+
+	dialect, ok := DetectDialect(dotnetTrace)
+	if !ok {
+		t.Fatal("expected a dialect match for .NET trace")
+	}
+	if dialect.Name() != "dotnet" {
+		t.Errorf("Name() = %q, want %q", dialect.Name(), "dotnet")
+	}
+	if got := DetectLanguage(dotnetTrace); got != models.LanguageDotNet {
+		t.Errorf("DetectLanguage() = %q, want %q", got, models.LanguageDotNet)
+	}
+
+	rubyTrace := "app.rb:10:in `foo': undefined method `bar' for nil:NilClass (NoMethodError)\n" +
+		"\tfrom app.rb:6:in `baz'\n" +
+		"\tfrom app.rb:2:in `<main>'"
+
+	dialect, ok = DetectDialect(rubyTrace)
+	if !ok {
+		t.Fatal("expected a dialect match for Ruby trace")
+	}
+	if dialect.Name() != "ruby" {
+		t.Errorf("Name() = %q, want %q", dialect.Name(), "ruby")
+	}
+	if got := DetectLanguage(rubyTrace); got != models.LanguageRuby {
+		t.Errorf("DetectLanguage() = %q, want %q", got, models.LanguageRuby)
+	}
+}
+
+func TestSetDisabledDialectsExcludesFromAutoDetection(t *testing.T) {
+	javaTrace := "Exception in thread \"main\" java.lang.NullPointerException: boom\n" +
+		"\tat com.example.Foo.bar(Foo.java:42)\n" +
+		"\tat com.example.Main.main(Main.java:5)"
+
+	SetDisabledDialects([]string{"java"})
+	defer SetDisabledDialects(nil)
+
+	if _, ok := DetectDialect(javaTrace); ok {
+		t.Error("expected disabled \"java\" dialect to be excluded from DetectDialect")
+	}
+	if _, ok := LookupDialect("java"); !ok {
+		t.Error("expected LookupDialect to still find a disabled dialect for explicit selection")
+	}
+}
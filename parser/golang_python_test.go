@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	goTrace := "goroutine 1 [running]:\n" +
+		"main.foo()\n" +
+		"\t/app/main.go:10 +0x25\n" +
+		"created by main.main\n" +
+		"\t/app/main.go:5 +0x10"
+
+	pythonTrace := "Traceback (most recent call last):\n" +
+		"  File \"app.py\", line 10, in foo\n" +
+		"    foo()\n" +
+		"ValueError: bad input"
+
+	jsTrace := "TypeError: Cannot read property 'map' of undefined\n" +
+		"    at Component.render (app.js:15:20)\n" +
+		"    at Component.render (app.js:15:20)"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Go goroutine trace", goTrace, models.LanguageGo},
+		{"Python traceback", pythonTrace, models.LanguagePython},
+		{"JavaScript trace", jsTrace, models.LanguageJavaScript},
+		{"Plain text", "just some regular text", models.LanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.input); got != tt.expected {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsStackTraceRecognizesGoAndPython(t *testing.T) {
+	goTrace := "goroutine 1 [running]:\n" +
+		"main.foo()\n" +
+		"\t/app/main.go:10 +0x25\n" +
+		"created by main.main\n" +
+		"\t/app/main.go:5 +0x10"
+
+	pythonTrace := "Traceback (most recent call last):\n" +
+		"  File \"app.py\", line 10, in foo\n" +
+		"    foo()\n" +
+		"ValueError: bad input"
+
+	if !IsStackTrace(goTrace) {
+		t.Error("IsStackTrace should detect a Go goroutine dump")
+	}
+	if !IsStackTrace(pythonTrace) {
+		t.Error("IsStackTrace should detect a Python traceback")
+	}
+}
+
+func TestCleanGoStackTraceCollapsesDuplicateGoroutines(t *testing.T) {
+	block := "goroutine %d [chan receive]:\n" +
+		"main.worker()\n" +
+		"\t/app/worker.go:20 +0x40\n" +
+		"created by main.main\n" +
+		"\t/app/main.go:8 +0x15"
+
+	input := strings.Join([]string{
+		fmt.Sprintf(block, 1),
+		fmt.Sprintf(block, 2),
+		fmt.Sprintf(block, 3),
+	}, "\n\n")
+
+	result := CleanResult(input)
+
+	if result.Language != models.LanguageGo {
+		t.Fatalf("expected language %q, got %q", models.LanguageGo, result.Language)
+	}
+	if result.Removed != 2 {
+		t.Errorf("Removed = %d, want 2 duplicate goroutines collapsed", result.Removed)
+	}
+	if strings.Count(result.Cleaned, "created by main.main") != 1 {
+		t.Error("expected the outermost created-by frame to survive exactly once")
+	}
+	if !strings.Contains(result.Cleaned, "[x3]") {
+		t.Error("expected the collapsed goroutine header to be annotated with its count")
+	}
+}
+
+func TestCleanPythonStackTraceCollapsesRecursionBurst(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Traceback (most recent call last):\n")
+	for i := 0; i < 8; i++ {
+		b.WriteString("  File \"app.py\", line 10, in recurse\n")
+		b.WriteString("    recurse(n - 1)\n")
+	}
+	b.WriteString("RecursionError: maximum recursion depth exceeded")
+
+	result := CleanResult(b.String())
+
+	if result.Language != models.LanguagePython {
+		t.Fatalf("expected language %q, got %q", models.LanguagePython, result.Language)
+	}
+	if result.Removed <= 0 {
+		t.Error("expected recursive frames to be collapsed")
+	}
+	if !strings.Contains(result.Cleaned, "more identical") {
+		t.Error("expected a summary annotation for the collapsed burst")
+	}
+}
@@ -1,12 +1,18 @@
 package parser
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"com.github/rethunk-tech/tracetrim/internal/models"
+	"com.github/rethunk-tech/tracetrim/internal/parser/codeframe"
 )
 
 const (
@@ -28,6 +34,21 @@ const (
 	// Pattern matching constants
 	minReactPatternMatches  = 4
 	minSourcePatternMatches = 3
+
+	// maxCustomPatterns bounds how many user-defined patterns may be installed
+	// via SetCustomPatterns, and maxCustomPatternLength bounds a single
+	// pattern's source length. This is a coarse complexity budget: Go's
+	// regexp engine (RE2) can't backtrack catastrophically the way
+	// V8/irregexp can, but a very long pattern or a very large set still
+	// costs compile time and per-line matching time.
+	maxCustomPatterns      = 50
+	maxCustomPatternLength = 500
+
+	// customPatternMatchTimeout bounds how long a single custom pattern may
+	// spend matching one line. Defense in depth against pathologically long
+	// input lines, since custom patterns come from user configuration rather
+	// than this package's own reviewed patterns.
+	customPatternMatchTimeout = 250 * time.Millisecond
 )
 
 // Pre-compiled regex patterns for better performance
@@ -35,7 +56,11 @@ var (
 	// Stack trace detection patterns - enhanced for better edge case handling
 	stackTracePatterns = []*regexp.Regexp{
 		// JavaScript stack trace patterns - more precise to avoid false matches
-		regexp.MustCompile(`\bat\s+[\w<>.()\s]+\s*\([^)]+\)`),          // "at functionName (file.js:123:45)" - allow more chars in function names
+		// Requires a space before "(" and a "file:line:col" suffix inside it, which
+		// distinguishes "at functionName (file.js:123:45)" from Java's
+		// "at com.example.Foo.bar(Foo.java:42)" (no space before "(", single line
+		// number) so this doesn't shadow the java dialect when it's disabled.
+		regexp.MustCompile(`\bat\s+[\w<>.\s]+\s+\([^():]+:\d+:\d+\)`),
 		regexp.MustCompile(`\b\w+\.(js|ts|jsx|tsx|mjs):\d+:\d+\b`),     // Support more file extensions
 		regexp.MustCompile(`(?m)^Error:\s+.*\n\s+at\s+`),               // "Error: message\n    at" - multiline
 		regexp.MustCompile(`\breact-dom\.development\.js`),             // React DOM development file
@@ -48,23 +73,244 @@ var (
 		// React console output patterns
 		regexp.MustCompile(`\b\w+\s+@\s+.+?:\d+\b`),                // "functionName @ file:line" - React console format (more permissive)
 		regexp.MustCompile(`\b\w+\.(js|ts|jsx|tsx|mjs|cjs):\d+\b`), // File paths with line numbers (without column)
+		// React componentStack entries, e.g. "    in Button (at App.js:12)"
+		regexp.MustCompile(`(?m)^\s*in\s+\S+\s+\((?:at\s+\S+:\d+|created by\s+\S+)\)\s*$`),
 	}
 
 	// Frame parsing patterns - enhanced for better edge case handling
 	framePattern      = regexp.MustCompile(`(.+?)\s*\(([^:()]+):(\d+):(\d+)\)`)
 	sourceFilePattern = regexp.MustCompile(`\.(js|ts|jsx|tsx|mjs|cjs):(\d+):(\d+)`)
+	// nativeFramePattern matches a frame that names a native/engine call
+	// instead of a file:line:col, e.g. "at Array.forEach (<anonymous>)",
+	// "at foo (native)", or "at foo ([native code])". These never have a
+	// line/column, so they don't match framePattern above.
+	nativeFramePattern = regexp.MustCompile(`^\s*at\s+(.+?)\s*\((<anonymous>|native|\[native code\])\)\s*$`)
 	// React console format patterns
 	reactFramePattern = regexp.MustCompile(`(.+?)\s*@\s*(.+?):(\d+)`)
 	// Enhanced component patterns for React lifecycle methods
 	componentPattern = regexp.MustCompile(`(\w+)\.(render|componentDidMount|componentDidUpdate|componentWillUnmount)\s*\(`)
+	// componentStackFramePattern matches one entry of a React componentStack
+	// string (the separate stack React error boundaries and devtools attach
+	// to an error, alongside the engine's own error.stack), e.g.
+	// "    in Button (at App.js:12)" or "    in Suspense (created by App)".
+	// Mirrors the normalization regex React's own Flight tests use to walk a
+	// componentStack: /\n +(?:at|in) ([\S]+)[^\n]*/g.
+	componentStackFramePattern = regexp.MustCompile(`^\s*in\s+(\S+)(?:\s+\(at\s+([^():]+):(\d+)\))?(?:\s+\(created by\s+(\S+)\))?\s*$`)
 	// Additional pattern for source file extraction with better path handling
 	sourceFileAltPattern = regexp.MustCompile(`\(([^:()]+):(\d+):(\d+)\)`)
 	// React console format for source file extraction
 	sourceFileReactPattern = regexp.MustCompile(`@\s*(.+?):(\d+)`)
+
+	// Go runtime.Stack patterns
+	goroutineHeaderPattern = regexp.MustCompile(`^goroutine \d+ \[[^\]]+\]:$`)
+	goLocationPattern      = regexp.MustCompile(`^\t(.+\.go):(\d+)(?:\s+\+0x[0-9a-fA-F]+)?$`)
+	goCreatedByPattern     = regexp.MustCompile(`^created by `)
+
+	// Python traceback patterns
+	pythonTracebackHeaderPattern  = regexp.MustCompile(`^Traceback \(most recent call last\):$`)
+	pythonFileLinePattern         = regexp.MustCompile(`^\s*File "(.+)", line (\d+), in (.+)$`)
+	pythonChainedExceptionPattern = regexp.MustCompile(`^(During handling of the above exception, another exception occurred:|The above exception was the direct cause of the following exception:)$`)
+
+	// Java/Kotlin exception patterns
+	javaStackTraceHeaderPattern = regexp.MustCompile(`^(Exception in thread "[^"]*"\s+)?[\w$.]+(?:Exception|Error)\b`)
+	javaFramePattern            = regexp.MustCompile(`^\s*at\s+[\w$.<>]+\([^:()]*(?::\d+)?\)\s*$`)
+	javaCausedByPattern         = regexp.MustCompile(`^Caused by:\s+`)
+
+	// .NET exception patterns. Frames always name the source file with "in
+	// File.cs:line N", which is what distinguishes them from Java's frames.
+	dotnetStackTraceHeaderPattern = regexp.MustCompile(`^(Unhandled exception\.\s+)?[\w.]+Exception\b`)
+	dotnetFramePattern            = regexp.MustCompile(`^\s*at\s+[\w.<>\[\],+ ]+\(.*\)\s+in\s+.+:line\s+\d+\s*$`)
+
+	// Ruby backtrace patterns. The raising line and every "from" line share the
+	// same "file.rb:N:in `method'" shape; only the "from" prefix differs.
+	rubyFramePattern = regexp.MustCompile("^\\s*(?:from\\s+)?\\S+\\.rb:\\d+:in `[^']+'")
+
+	// customPatterns holds user-defined detection patterns installed via
+	// SetCustomPatterns, consulted alongside (not in place of) the built-in
+	// patterns above by isJavaScriptStackTrace, extractFrameSignature, and
+	// extractSourceInfo.
+	customPatterns []CustomPattern
 )
 
-// IsStackTrace determines if the given content contains a JavaScript or React stack trace
-// Optimized to avoid allocations for short content and improve performance
+// CustomPattern is a single compiled entry from a CustomPatternSpecs string
+// (see ParseCustomPatternSpecs): a pattern, the minimum stack-line count it
+// requires for content to qualify as a stack trace, and a priority used to
+// break ties when more than one custom pattern matches the same content.
+type CustomPattern struct {
+	Pattern  *regexp.Regexp
+	MinLines int
+	Priority int
+}
+
+// ParseCustomPatternSpecs parses raw using klog's -vmodule syntax: a
+// comma-separated list of "pattern=minLines" entries (e.g.
+// "runtime\\.=3,net/http=1,mypkg\\.=2"). A bare entry with no "=minLines"
+// gets defaultMinLines. Entries are compiled in order, and the first entry in
+// raw is given the highest Priority, so it wins ties when isJavaScriptStackTrace
+// sees more than one custom pattern match within the same content.
+func ParseCustomPatternSpecs(raw string, defaultMinLines int) ([]CustomPattern, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	tokens := strings.Split(raw, ",")
+	if len(tokens) > maxCustomPatterns {
+		return nil, fmt.Errorf("too many custom patterns: %d exceeds the limit of %d", len(tokens), maxCustomPatterns)
+	}
+
+	specs := make([]CustomPattern, 0, len(tokens))
+	for i, token := range tokens {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return nil, fmt.Errorf("custom pattern at index %d cannot be empty", i)
+		}
+		if len(token) > maxCustomPatternLength {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) exceeds the %d character limit", i, token, maxCustomPatternLength)
+		}
+
+		patternSrc, minLines, hasLevel := splitPatternLevel(token)
+		re, err := regexp.Compile(patternSrc)
+		if err != nil {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) is not a valid regex: %w", i, token, err)
+		}
+		if dup := DuplicateSubexpName(re); dup != "" {
+			return nil, fmt.Errorf("custom pattern at index %d (%q) uses named capture group %q more than once", i, token, dup)
+		}
+		if !hasLevel {
+			minLines = defaultMinLines
+		}
+
+		specs = append(specs, CustomPattern{
+			Pattern:  re,
+			MinLines: minLines,
+			Priority: len(tokens) - i,
+		})
+	}
+
+	return specs, nil
+}
+
+// DuplicateSubexpName returns the first named capture group that re.SubexpNames
+// repeats, or "" if all names are unique. Go's regexp.Compile accepts duplicate
+// named groups (unlike PCRE), so this check has to be done separately. Exported
+// so internal/config can run the same check at config-validation time that
+// ParseCustomPatternSpecs runs at load time, rather than re-implementing it and
+// risking the two drifting apart.
+func DuplicateSubexpName(re *regexp.Regexp) string {
+	seen := make(map[string]bool)
+	for _, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return name
+		}
+		seen[name] = true
+	}
+	return ""
+}
+
+// splitPatternLevel splits a "pattern=minLines" token on its last "=". If
+// what follows isn't a valid integer, the whole token is treated as a bare
+// pattern, so a regex that itself contains "=" still works.
+func splitPatternLevel(token string) (pattern string, minLines int, hasLevel bool) {
+	idx := strings.LastIndex(token, "=")
+	if idx < 0 {
+		return token, 0, false
+	}
+
+	n, err := strconv.Atoi(token[idx+1:])
+	if err != nil {
+		return token, 0, false
+	}
+
+	return token[:idx], n, true
+}
+
+// SetCustomPatterns parses raw (see ParseCustomPatternSpecs) and installs the
+// resulting patterns, rejecting any entry that fails to parse or exceeds the
+// complexity budget (maxCustomPatterns, maxCustomPatternLength). Patterns may
+// use the named capture groups "func", "file", "line", and "col" so a match
+// feeds extractFrameSignature and extractSourceInfo the same way the built-in
+// patterns do.
+func SetCustomPatterns(raw string, defaultMinLines int) error {
+	parsed, err := ParseCustomPatternSpecs(raw, defaultMinLines)
+	if err != nil {
+		return err
+	}
+
+	customPatterns = parsed
+	return nil
+}
+
+// matchWithTimeout runs pattern.MatchString(line) on a goroutine and reports
+// false if it doesn't complete within customPatternMatchTimeout. Go's regexp
+// engine (RE2) guarantees linear-time matching rather than catastrophic
+// backtracking, but this still bounds how long a pathologically long input
+// line can occupy a user-supplied pattern.
+func matchWithTimeout(pattern *regexp.Regexp, line string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), customPatternMatchTimeout)
+	defer cancel()
+
+	result := make(chan bool, 1)
+	go func() {
+		result <- pattern.MatchString(line)
+	}()
+
+	select {
+	case matched := <-result:
+		return matched
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// matchCustomFrame tries each installed custom pattern against line in order,
+// extracting its "func", "file", "line", and "col" named capture groups.
+// Patterns without a "file" group are skipped since they can't identify a
+// frame's location.
+func matchCustomFrame(line string) (functionName, fileName, lineNumber, col string, ok bool) {
+	for _, cp := range customPatterns {
+		pattern := cp.Pattern
+		if !matchWithTimeout(pattern, line) {
+			continue
+		}
+
+		matches := pattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		names := pattern.SubexpNames()
+		var funcVal, fileVal, lineVal, colVal string
+		for i, name := range names {
+			if i == 0 || i >= len(matches) {
+				continue
+			}
+			switch name {
+			case "func":
+				funcVal = matches[i]
+			case "file":
+				fileVal = matches[i]
+			case "line":
+				lineVal = matches[i]
+			case "col":
+				colVal = matches[i]
+			}
+		}
+
+		if fileVal == "" {
+			continue
+		}
+
+		return funcVal, fileVal, lineVal, colVal, true
+	}
+
+	return "", "", "", "", false
+}
+
+// IsStackTrace determines if the given content is recognized by any enabled
+// dialect (JavaScript/React, Go, Python, Java/Kotlin, .NET, or Ruby).
 func IsStackTrace(content string) bool {
 	// Validate input content first
 	if !isValidContent(content) {
@@ -76,24 +322,130 @@ func IsStackTrace(content string) bool {
 		return false
 	}
 
+	_, ok := DetectDialect(content)
+	return ok
+}
+
+// isJavaScriptStackTrace determines if content contains a JavaScript or React stack trace
+func isJavaScriptStackTrace(content string) bool {
 	lines := strings.Split(content, "\n")
 	stackLineCount := 0
 
+	// threshold is how many stack-like lines are required before content
+	// qualifies as a trace. It starts at the package default and is
+	// overridden by whichever custom pattern has matched so far with the
+	// highest Priority, so a more specific custom pattern's MinLines wins
+	// over a less specific one.
+	threshold := minStackLinesForDetection
+	bestPriority := -1
+
 	for _, line := range lines {
 		if line == "" {
 			continue
 		}
 
-		// Check if this line matches any stack trace pattern
+		// Check if this line matches any stack trace pattern, built-in or
+		// user-defined
+		matched := false
 		for _, pattern := range stackTracePatterns {
 			if pattern.MatchString(line) {
-				stackLineCount++
+				matched = true
 				break
 			}
 		}
+		if !matched {
+			for _, cp := range customPatterns {
+				if matchWithTimeout(cp.Pattern, line) {
+					matched = true
+					if cp.Priority > bestPriority {
+						bestPriority = cp.Priority
+						threshold = cp.MinLines
+					}
+					break
+				}
+			}
+		}
+		if matched {
+			stackLineCount++
+		}
 
 		// If we find multiple stack-like lines, it's likely a stack trace
-		if stackLineCount >= minStackLinesForDetection {
+		if stackLineCount >= threshold {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGoStackTrace determines if content contains a Go runtime.Stack-style trace, i.e.
+// one or more "goroutine N [state]:" headers or a runtime.goexit frame.
+func isGoStackTrace(content string) bool {
+	if strings.Contains(content, "runtime.goexit") {
+		return true
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if goroutineHeaderPattern.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPythonStackTrace determines if content contains a Python traceback, i.e. a
+// "Traceback (most recent call last):" header.
+func isPythonStackTrace(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if pythonTracebackHeaderPattern.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isJavaStackTrace determines if content contains a Java or Kotlin exception trace,
+// i.e. an exception header ("java.lang.NullPointerException: ..." or
+// "Exception in thread \"main\" ...", optionally introduced by "Caused by:") together
+// with at least one "\tat pkg.Class.method(File.java:NN)" frame.
+func isJavaStackTrace(content string) bool {
+	hasHeader := false
+	hasFrame := false
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if javaStackTraceHeaderPattern.MatchString(trimmed) || javaCausedByPattern.MatchString(trimmed) {
+			hasHeader = true
+		}
+		if javaFramePattern.MatchString(line) {
+			hasFrame = true
+		}
+		if hasHeader && hasFrame {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDotNetStackTrace determines if content contains a .NET exception trace, i.e. an
+// exception header ("System.NullReferenceException: ..." or "Unhandled exception. ...")
+// together with at least one "   at Namespace.Class.Method() in File.cs:line N" frame.
+func isDotNetStackTrace(content string) bool {
+	hasHeader := false
+	hasFrame := false
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if dotnetStackTraceHeaderPattern.MatchString(trimmed) {
+			hasHeader = true
+		}
+		if dotnetFramePattern.MatchString(line) {
+			hasFrame = true
+		}
+		if hasHeader && hasFrame {
 			return true
 		}
 	}
@@ -101,6 +453,31 @@ func IsStackTrace(content string) bool {
 	return false
 }
 
+// isRubyStackTrace determines if content contains a Ruby backtrace, i.e. at least two
+// "file.rb:N:in `method'" lines (the raising line plus one or more "from" lines).
+func isRubyStackTrace(content string) bool {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		if rubyFramePattern.MatchString(strings.TrimSpace(line)) {
+			count++
+		}
+	}
+
+	return count >= 2
+}
+
+// DetectLanguage identifies which stack trace dialect content is written in, returning
+// one of the models.Language* constants (models.LanguageUnknown if none match), honoring
+// the same registration-order precedence and enabled/disabled dialects as DetectDialect.
+func DetectLanguage(content string) string {
+	dialect, ok := DetectDialect(content)
+	if !ok {
+		return models.LanguageUnknown
+	}
+
+	return LanguageForDialect(dialect.Name())
+}
+
 // isValidContent validates that the content is safe to process
 func isValidContent(content string) bool {
 	// Check if content is valid UTF-8
@@ -113,7 +490,9 @@ func isValidContent(content string) bool {
 		return false
 	}
 
-	// Check content length is reasonable (prevent memory exhaustion)
+	// Check content length is reasonable (prevent memory exhaustion). Input
+	// larger than this should go through StreamClean instead, which never
+	// holds the whole thing in memory.
 	if len(content) > 50*1024*1024 { // 50MB limit
 		return false
 	}
@@ -133,12 +512,23 @@ func isValidContent(content string) bool {
 type CleanResultPair struct {
 	Content string
 	Removed int
+
+	// Frames is the structured per-frame breakdown of Content, for callers
+	// that need machine-readable data (e.g. JSON/SARIF output) rather than
+	// just the rewritten text. Only cleanJavaScriptStackTrace populates this
+	// today; other dialects leave it nil.
+	Frames []models.StackFrame
+
+	// RemovedGroups lists the frame signatures Content collapsed duplicates
+	// of. Only cleanJavaScriptStackTrace populates this today.
+	RemovedGroups []models.RemovedGroup
 }
 
 // CleanStackTrace removes repetitive stack trace blocks while preserving all original formatting.
 // isStackFrameLine checks if a line contains a stack frame pattern
 func isStackFrameLine(line string) bool {
-	return framePattern.MatchString(line) || reactFramePattern.MatchString(line)
+	return framePattern.MatchString(line) || reactFramePattern.MatchString(line) ||
+		componentStackFramePattern.MatchString(line) || nativeFramePattern.MatchString(line)
 }
 
 // countFrameOccurrences counts how many times each frame signature appears
@@ -155,31 +545,52 @@ func countFrameOccurrences(lines []string) map[string]int {
 	return frameCounts
 }
 
-// buildCleanedLines creates cleaned lines, removing duplicate frames
-func buildCleanedLines(lines []string) (cleanedLines []string, framesCollapsed int) {
+// buildCleanedLines creates cleaned lines, removing duplicate frames. A line
+// whose index is in protected is always kept and marks its signature seen
+// without being treated as a duplicate itself - used for the first/last
+// occurrence block a recursion cycle (see collapseRecursionCyclesLines)
+// already decided to keep, so a later stray duplicate of the same frame
+// elsewhere in the trace still gets removed normally. strayGroups reports,
+// per distinct frame signature stripped this way, how many occurrences were
+// removed.
+func buildCleanedLines(lines []string, protected map[int]bool) (cleanedLines []string, framesCollapsed int, strayGroups []models.RemovedGroup) {
 	seenFrames := make(map[string]bool)
+	strayCounts := make(map[string]int)
+	strayNames := make(map[string]string)
+	var strayOrder []string
 
-	for _, line := range lines {
+	for i, line := range lines {
 		originalLine := line
-		line = strings.TrimSpace(line)
+		trimmed := strings.TrimSpace(line)
 
-		if line == "" {
+		if trimmed == "" {
 			// Preserve empty lines
 			cleanedLines = append(cleanedLines, originalLine)
 			continue
 		}
 
-		if !isStackFrameLine(line) {
+		if !isStackFrameLine(trimmed) {
 			// Non-frame lines are preserved as-is
 			cleanedLines = append(cleanedLines, originalLine)
 			continue
 		}
 
-		frameSignature := extractFrameSignature(line)
+		frameSignature := extractFrameSignature(trimmed)
+
+		if protected[i] {
+			seenFrames[frameSignature] = true
+			cleanedLines = append(cleanedLines, originalLine)
+			continue
+		}
 
 		if seenFrames[frameSignature] {
 			// This is a duplicate frame - skip it
 			framesCollapsed++
+			if strayCounts[frameSignature] == 0 {
+				strayOrder = append(strayOrder, frameSignature)
+				strayNames[frameSignature] = frameDisplayName(trimmed)
+			}
+			strayCounts[frameSignature]++
 			continue
 		}
 
@@ -188,26 +599,86 @@ func buildCleanedLines(lines []string) (cleanedLines []string, framesCollapsed i
 		cleanedLines = append(cleanedLines, originalLine)
 	}
 
-	return cleanedLines, framesCollapsed
+	for _, signature := range strayOrder {
+		strayGroups = append(strayGroups, models.RemovedGroup{Frame: strayNames[signature], Count: strayCounts[signature]})
+	}
+
+	return cleanedLines, framesCollapsed, strayGroups
 }
 
-// annotateDuplicateFrames adds annotations to frames that had duplicates
-func annotateDuplicateFrames(cleanedLines []string, frameCounts map[string]int) {
-	for i, line := range cleanedLines {
-		lineTrimmed := strings.TrimSpace(line)
-		if lineTrimmed == "" {
-			continue
-		}
+// maxCycleDetectionFrames bounds the cycle scan below, the same way other
+// O(n^2) scans in this package (e.g. matchWithTimeout) cap their input so a
+// pathologically long trace can't make cleaning itself slow.
+const maxCycleDetectionFrames = 500
+
+// DetectCycles finds contiguous periodic repeats in a sequence of frame
+// signatures (as produced by extractFrameSignature) - e.g. mutual recursion
+// like "A B A B A B", not just the same frame recurring at unrelated points
+// in the trace. For each position it tries the smallest period p for which
+// frames[i:i+p] repeats k>=2 times back to back, records that as a cycle,
+// then jumps past the consumed block so an outer period can't re-swallow an
+// inner one (e.g. "A B C B C B C D" reports only the inner "B C" cycle).
+// Only the first maxCycleDetectionFrames signatures are scanned.
+func DetectCycles(signatures []string) []models.CycleInfo {
+	n := len(signatures)
+	if n > maxCycleDetectionFrames {
+		n = maxCycleDetectionFrames
+	}
 
-		if isStackFrameLine(lineTrimmed) {
-			frameSignature := extractFrameSignature(lineTrimmed)
-			if count := frameCounts[frameSignature]; count > 1 {
-				// This frame has duplicates - annotate it
-				collapsedLine := fmt.Sprintf("%s // [x%d]", line, count)
-				cleanedLines[i] = collapsedLine
+	var cycles []models.CycleInfo
+	for i := 0; i < n; {
+		found := false
+		for p := 1; i+2*p <= n; p++ {
+			reps := 1
+			for i+(reps+1)*p <= n && equalSignatureBlocks(signatures[i+reps*p:i+(reps+1)*p], signatures[i:i+p]) {
+				reps++
 			}
+			if reps >= 2 {
+				cycles = append(cycles, models.CycleInfo{StartFrame: i, Length: p, Repetitions: reps})
+				i += p * reps
+				found = true
+				break
+			}
+		}
+		if !found {
+			i++
 		}
 	}
+	return cycles
+}
+
+func equalSignatureBlocks(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// FrameSignatures returns the frame signature of every JS/React stack frame
+// line found in content, in order - the input DetectCycles expects. Callers
+// that already have content split into lines should use frameSignatures
+// instead to avoid splitting twice.
+func FrameSignatures(content string) []string {
+	return frameSignatures(strings.Split(content, "\n"))
+}
+
+// frameSignatures returns the frame signature of every stack frame line in
+// lines, in order, skipping non-frame lines - the input DetectCycles expects.
+func frameSignatures(lines []string) []string {
+	var sigs []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || !isStackFrameLine(trimmed) {
+			continue
+		}
+		sigs = append(sigs, extractFrameSignature(trimmed))
+	}
+	return sigs
 }
 
 // extractErrorMessage extracts the error message from the first line of stack trace
@@ -226,6 +697,15 @@ func shouldIncludeSource(filename string) bool {
 
 // extractSourceInfo attempts to extract source file and line information from a stack frame line
 func extractSourceInfo(line string) string {
+	// Try user-defined patterns first, so a configured "file"/"line"/"col"
+	// capture takes precedence over the built-in heuristics below
+	if _, fileName, lineNumber, col, ok := matchCustomFrame(line); ok && shouldIncludeSource(fileName) {
+		if col != "" {
+			return fmt.Sprintf("%s:%s:%s", fileName, lineNumber, col)
+		}
+		return fmt.Sprintf("%s:%s", fileName, lineNumber)
+	}
+
 	// Try React console format first: "functionName @ file.js:123"
 	if reactMatches := sourceFileReactPattern.FindStringSubmatch(line); len(reactMatches) >= minSourcePatternMatches {
 		filename := strings.TrimSpace(reactMatches[1])
@@ -282,16 +762,288 @@ func CleanStackTrace(content string) CleanResultPair {
 		return CleanResultPair{Content: content, Removed: 0}
 	}
 
-	lines := strings.Split(content, "\n")
-	frameCounts := countFrameOccurrences(lines)
-	cleanedLines, framesCollapsed := buildCleanedLines(lines)
-	annotateDuplicateFrames(cleanedLines, frameCounts)
+	if dialect, ok := DetectDialect(content); ok {
+		return dialect.Clean(content)
+	}
+	return cleanJavaScriptStackTrace(content)
+}
+
+// SourceMapResolver maps a generated JS/TS frame's file/line/column back to its
+// original source position. internal/sourcemap.Resolver implements this; parser
+// only depends on the interface so it doesn't need to import that package.
+type SourceMapResolver interface {
+	Resolve(file string, line, col int) (origFile string, origLine, origCol int, ok bool)
+}
+
+// sourceMapResolver is consulted by cleanJavaScriptStackTrace and ExtractErrorInfo
+// to resolve bundled/minified frames to their original source position. Nil (the
+// default) disables source-map resolution entirely.
+var sourceMapResolver SourceMapResolver
+
+// SetSourceMapResolver installs (or, with nil, removes) the resolver used to
+// annotate JS/TS frames with their original source position.
+func SetSourceMapResolver(r SourceMapResolver) {
+	sourceMapResolver = r
+}
+
+// withSourceMapResolver runs fn with resolver installed as the package-level
+// sourceMapResolver, restoring whatever was previously installed afterward.
+// Not safe to call concurrently with another goroutine that also installs a
+// resolver (SetSourceMapResolver, or this same function) - callers that need
+// per-call resolvers from multiple goroutines should serialize around this,
+// the same caveat SetSourceMapResolver already carries as a package-level var.
+func withSourceMapResolver(resolver SourceMapResolver, fn func()) {
+	previous := sourceMapResolver
+	sourceMapResolver = resolver
+	defer func() { sourceMapResolver = previous }()
+	fn()
+}
+
+// CleanStackTraceWithSourceMaps is CleanStackTrace with resolver used for
+// this call's source-map resolution instead of whatever SetSourceMapResolver
+// last installed. Frames the resolver can't map (a missing ".map" file, or no
+// mapping at that position) fall back to the frame's own generated position,
+// same as CleanStackTrace.
+func CleanStackTraceWithSourceMaps(content string, resolver SourceMapResolver) CleanResultPair {
+	var result CleanResultPair
+	withSourceMapResolver(resolver, func() {
+		result = CleanStackTrace(content)
+	})
+	return result
+}
+
+// CleanResultWithSourceMaps is CleanResult with resolver used for this call's
+// source-map resolution instead of whatever SetSourceMapResolver last
+// installed.
+func CleanResultWithSourceMaps(content string, resolver SourceMapResolver) models.CleanResult {
+	var result models.CleanResult
+	withSourceMapResolver(resolver, func() {
+		result = CleanResult(content)
+	})
+	return result
+}
+
+// parseGeneratedFrameLocation extracts a JS/React frame's generated
+// file/line/column from line, without any source-map resolution.
+func parseGeneratedFrameLocation(line string) (file string, genLine int, genCol int, ok bool) {
+	if matches := framePattern.FindStringSubmatch(line); len(matches) >= minFunctionPatternMatches {
+		genLine, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return "", 0, 0, false
+		}
+		genCol, err := strconv.Atoi(matches[4])
+		if err != nil {
+			return "", 0, 0, false
+		}
+		return matches[2], genLine, genCol, true
+	}
+
+	if matches := reactFramePattern.FindStringSubmatch(line); len(matches) >= minReactPatternMatches {
+		genLine, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return "", 0, 0, false
+		}
+		return matches[2], genLine, 0, true
+	}
+
+	return "", 0, 0, false
+}
+
+// resolveFrameSourceMap extracts a JS/React frame's generated file/line/column
+// from line and, if a resolver is configured, resolves it to an original source
+// position.
+func resolveFrameSourceMap(line string) (file string, origLine int, origCol int, ok bool) {
+	if sourceMapResolver == nil {
+		return "", 0, 0, false
+	}
+
+	file, genLine, genCol, ok := parseGeneratedFrameLocation(line)
+	if !ok {
+		return "", 0, 0, false
+	}
+
+	return sourceMapResolver.Resolve(file, genLine, genCol)
+}
+
+// annotateSourceMapFrames appends "// originally at src/Foo.tsx:42:8" to every
+// surviving frame line that a configured source map resolves to an original
+// source position, alongside (not replacing) any collapsed-count annotation.
+func annotateSourceMapFrames(cleanedLines []string) {
+	if sourceMapResolver == nil {
+		return
+	}
+
+	for i, line := range cleanedLines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		file, origLine, origCol, ok := resolveFrameSourceMap(trimmed)
+		if !ok {
+			continue
+		}
+
+		cleanedLines[i] = fmt.Sprintf("%s // originally at %s:%d:%d", line, file, origLine, origCol)
+	}
+}
+
+// codeFrameContextLines is the number of lines of source to show above and
+// below the error line in ExtractErrorInfo's CodeFrame output. Zero (the
+// default) disables code frame rendering entirely.
+var codeFrameContextLines int
+
+// SetCodeFrameContextLines sets how many lines of source context
+// ExtractErrorInfo renders around the top frame's error position. Zero
+// disables code frame rendering.
+func SetCodeFrameContextLines(n int) {
+	codeFrameContextLines = n
+}
+
+// bundledAssetMarkers identifies substrings of a source path that mean it
+// belongs to a built, third-party, or remote bundle rather than a user's own
+// source tree, so codeframe rendering is skipped for it rather than reading
+// (or fetching) the wrong file.
+var bundledAssetMarkers = []string{
+	"node_modules",
+	"react-dom.development.js",
+}
+
+// isBundledAssetPath reports whether file looks like a bundled asset: an
+// http(s) URL, or a path containing one of bundledAssetMarkers.
+func isBundledAssetPath(file string) bool {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		return true
+	}
+	for _, marker := range bundledAssetMarkers {
+		if strings.Contains(file, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCodeFrame renders a code frame for a JS/React frame line, preferring
+// its source-mapped original position when one resolves, falling back to the
+// frame's own generated file/line/column otherwise. Returns "" if no local
+// file can be read at the resolved location, or if that location looks like
+// a bundled asset rather than the user's own source.
+func buildCodeFrame(line string) string {
+	if codeFrameContextLines <= 0 {
+		return ""
+	}
+
+	file, lineNum, col, ok := resolveFrameSourceMap(line)
+	if !ok {
+		file, lineNum, col, ok = parseGeneratedFrameLocation(line)
+	}
+	if !ok || isBundledAssetPath(file) {
+		return ""
+	}
+
+	sourceCode, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	return codeframe.Generate(string(sourceCode), lineNum, col, codeFrameContextLines)
+}
+
+// CleanOptions configures per-call codeframe rendering for CleanResultWithOptions,
+// as an alternative to the package-level knobs (SetCodeFrameContextLines,
+// codeframe.EnableColor) CleanResult uses. Useful when a caller handles
+// multiple workspaces or wants different rendering per call rather than one
+// global setting.
+type CleanOptions struct {
+	// Root is the directory a frame's source file is resolved relative to
+	// when its own path isn't absolute. Empty resolves paths the same way
+	// CleanResult does: relative to the process's working directory.
+	Root string
+
+	// ContextLines is how many lines of source to show above and below the
+	// error line (0 disables codeframe rendering entirely).
+	ContextLines int
+
+	// TabWidth expands tabs in the rendered source to this many spaces, so
+	// the caret stays visually under the right character. 0 leaves tabs
+	// as-is (a tab counts as one column, same as CleanResult's behavior).
+	TabWidth int
+
+	// Colorize wraps the codeframe in ANSI color codes.
+	Colorize bool
+}
+
+// buildCodeFrameWithOptions is buildCodeFrame parameterized by opts instead
+// of the package-level codeFrameContextLines/codeframe.EnableColor, and able
+// to resolve a relative source path against opts.Root. Skips rendering
+// silently (returns "") if the file can't be read, the resolved path looks
+// like a bundled asset, or the line is out of range (codeframe.Generate's
+// own check).
+func buildCodeFrameWithOptions(line string, opts CleanOptions) string {
+	if opts.ContextLines <= 0 {
+		return ""
+	}
+
+	file, lineNum, col, ok := resolveFrameSourceMap(line)
+	if !ok {
+		file, lineNum, col, ok = parseGeneratedFrameLocation(line)
+	}
+	if !ok || isBundledAssetPath(file) {
+		return ""
+	}
+
+	path := file
+	if opts.Root != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(opts.Root, path)
+	}
+
+	sourceCode, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	text := string(sourceCode)
+	if opts.TabWidth > 0 {
+		text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", opts.TabWidth))
+	}
+
+	prevColor := codeframe.EnableColor
+	codeframe.EnableColor = opts.Colorize
+	defer func() { codeframe.EnableColor = prevColor }()
+
+	return codeframe.Generate(text, lineNum, col, opts.ContextLines)
+}
+
+// cleanJavaScriptStackTrace collapses recursion cycles and repeated JS/React
+// stack frames, marking each collapsed cycle with a "// Recursion ... repeated
+// N times" comment and prefixing a "// Removed N repetitive stack frame(s)"
+// line when other duplicate frames were dropped.
+func cleanJavaScriptStackTrace(content string) CleanResultPair {
+	// Collapse genuine recursion cycles (a repeating frame *sequence*, down to
+	// its first and last occurrence plus a marker) before the plain duplicate
+	// removal below, so "A A A" reads as "// Recursion A repeated 3 times"
+	// rather than silently deleting every repeat.
+	collapsedLines, cycles, protected, cycleGroups := collapseRecursionCyclesLines(strings.Split(content, "\n"))
+
+	frameCounts := countFrameOccurrences(collapsedLines)
+	cleanedLines, framesCollapsed, strayGroups := buildCleanedLines(collapsedLines, protected)
+
+	// Build the structured frame list before annotateSourceMapFrames below
+	// rewrites lines with "// originally at ..." comments
+	frames := buildStackFrames(cleanedLines, frameCounts)
+	removedGroups := append(cycleGroups, strayGroups...)
+
+	annotateSourceMapFrames(cleanedLines)
 
 	// Use strings.Builder for efficient string concatenation
 	estimatedSize := len(content)
 	var builder strings.Builder
 	builder.Grow(estimatedSize)
 
+	if framesCollapsed > 0 {
+		builder.WriteString(fmt.Sprintf("// Removed %d repetitive stack frame(s)\n", framesCollapsed))
+	}
+
 	// Join cleaned lines
 	for i, line := range cleanedLines {
 		if i > 0 {
@@ -301,12 +1053,598 @@ func CleanStackTrace(content string) CleanResultPair {
 	}
 
 	result := builder.String()
-	return CleanResultPair{Content: result, Removed: framesCollapsed}
+	return CleanResultPair{Content: result, Removed: framesCollapsed + cycleFramesDropped(cycles), Frames: frames, RemovedGroups: removedGroups}
+}
+
+// cycleFramesDropped sums the frames each cycle in cycles actually removed -
+// every repetition strictly between the preserved first and last occurrence.
+func cycleFramesDropped(cycles []models.CycleInfo) int {
+	dropped := 0
+	for _, c := range cycles {
+		dropped += (c.Repetitions - 2) * c.Length
+	}
+	return dropped
+}
+
+// frameDisplayName returns a short human-readable name for a stack frame
+// line, for use in a recursion marker comment - the function name when one
+// parses out, the component name for a componentStack-only entry, or the
+// trimmed line itself as a last resort.
+func frameDisplayName(line string) string {
+	if frame, ok := buildStackFrame(line, 0); ok && frame.Function != "" {
+		return frame.Function
+	}
+	if name := extractComponentStackName(line); name != "" {
+		return name
+	}
+	return strings.TrimSpace(line)
+}
+
+// recursionMarker builds the "// Recursion A -> B -> C repeated N times"
+// comment for a detected cycle, naming each frame in one repetition of it.
+func recursionMarker(lines []string, repetitions int) string {
+	names := make([]string, len(lines))
+	for i, line := range lines {
+		names[i] = frameDisplayName(line)
+	}
+	return fmt.Sprintf("// Recursion %s repeated %d times", strings.Join(names, " -> "), repetitions)
+}
+
+// CollapseRecursionCycles rewrites content so that a frame sequence which
+// repeats as a whole (genuine recursion, e.g. "A B A B A B") is replaced by
+// its first and last occurrence plus a "// Recursion ... repeated N times"
+// marker, instead of the default CleanStackTrace behavior of deleting every
+// repeated frame signature wherever it appears. It returns the rewritten
+// content alongside the cycles that were found. Content with no detected
+// cycles is returned unchanged.
+func CollapseRecursionCycles(content string) (string, []models.CycleInfo) {
+	out, cycles, _, _ := collapseRecursionCyclesLines(strings.Split(content, "\n"))
+	return strings.Join(out, "\n"), cycles
+}
+
+// collapseRecursionCyclesLines does the line-level work behind
+// CollapseRecursionCycles, additionally reporting which indices of out hold a
+// cycle's preserved first/last occurrence block, so cleanJavaScriptStackTrace
+// can run its usual duplicate-frame removal afterward without re-collapsing
+// the block a cycle marker already accounts for, and the models.RemovedGroup
+// each cycle collapsed.
+func collapseRecursionCyclesLines(lines []string) (out []string, cycles []models.CycleInfo, protected map[int]bool, cycleGroups []models.RemovedGroup) {
+	var frameLineIdx []int
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && isStackFrameLine(trimmed) {
+			frameLineIdx = append(frameLineIdx, i)
+		}
+	}
+
+	cycles = DetectCycles(frameSignatures(lines))
+	if len(cycles) == 0 {
+		return lines, nil, nil, nil
+	}
+
+	// drop marks middle-occurrence lines to omit; markerAt maps the line
+	// index ending a cycle's first occurrence to the marker comment to
+	// insert right after it; keep marks the first/last occurrence block that
+	// survives into out.
+	drop := make(map[int]bool)
+	markerAt := make(map[int]string)
+	keep := make(map[int]bool)
+
+	for _, c := range cycles {
+		firstStart := frameLineIdx[c.StartFrame]
+		firstEnd := frameLineIdx[c.StartFrame+c.Length-1]
+		lastStart := frameLineIdx[c.StartFrame+(c.Repetitions-1)*c.Length]
+		lastEnd := frameLineIdx[c.StartFrame+c.Repetitions*c.Length-1]
+
+		cycleFrames := lines[firstStart : firstEnd+1]
+		markerAt[firstEnd] = recursionMarker(cycleFrames, c.Repetitions)
+
+		if dropped := (c.Repetitions - 2) * c.Length; dropped > 0 {
+			names := make([]string, len(cycleFrames))
+			for i, frameLine := range cycleFrames {
+				names[i] = frameDisplayName(frameLine)
+			}
+			cycleGroups = append(cycleGroups, models.RemovedGroup{Frame: strings.Join(names, " -> "), Count: dropped})
+		}
+
+		for i := firstStart; i <= firstEnd; i++ {
+			keep[i] = true
+		}
+		for i := lastStart; i <= lastEnd; i++ {
+			keep[i] = true
+		}
+
+		// Drop every repetition strictly between the first and the last.
+		for rep := 1; rep < c.Repetitions-1; rep++ {
+			start := frameLineIdx[c.StartFrame+rep*c.Length]
+			end := frameLineIdx[c.StartFrame+(rep+1)*c.Length-1]
+			for i := start; i <= end; i++ {
+				drop[i] = true
+			}
+		}
+	}
+
+	protected = make(map[int]bool)
+	for i, line := range lines {
+		if drop[i] {
+			continue
+		}
+		if keep[i] {
+			protected[len(out)] = true
+		}
+		out = append(out, line)
+		if marker, ok := markerAt[i]; ok {
+			out = append(out, marker)
+		}
+	}
+
+	return out, cycles, protected, cycleGroups
+}
+
+// buildStackFrames converts cleaned (pre-annotation) frame lines into
+// structured models.StackFrame values, so JSON/SARIF output has
+// machine-readable per-frame data instead of just the rewritten text.
+func buildStackFrames(cleanedLines []string, frameCounts map[string]int) []models.StackFrame {
+	var frames []models.StackFrame
+	firstIndexOf := make(map[string]int)
+
+	for _, line := range cleanedLines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || !isStackFrameLine(trimmed) {
+			continue
+		}
+
+		signature := extractFrameSignature(trimmed)
+		collapsedCount := frameCounts[signature] - 1
+		if collapsedCount < 0 {
+			collapsedCount = 0
+		}
+
+		frame, ok := buildStackFrame(line, collapsedCount)
+		if !ok {
+			continue
+		}
+
+		frame.RepeatCount = frameCounts[signature]
+		if first, seen := firstIndexOf[signature]; seen {
+			frame.IsRepeatOf = first + 1
+		} else {
+			firstIndexOf[signature] = len(frames)
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// buildStackFrame parses a single stack frame line into a structured
+// models.StackFrame, preferring user-defined patterns, then the standard
+// "at func (file:line:col)" format, then the React console "func @ file:line"
+// format. Returns ok=false if line doesn't match any of them.
+func buildStackFrame(line string, collapsedCount int) (models.StackFrame, bool) {
+	if functionName, fileName, lineNumber, col, ok := matchCustomFrame(line); ok {
+		lineNum, _ := strconv.Atoi(lineNumber)
+		colNum, _ := strconv.Atoi(col)
+		return models.StackFrame{
+			Function:       functionName,
+			File:           fileName,
+			Line:           lineNum,
+			Column:         colNum,
+			CollapsedCount: collapsedCount,
+			IsNative:       isNativeFrame(fileName),
+			Raw:            line,
+		}, true
+	}
+
+	if matches := framePattern.FindStringSubmatch(line); len(matches) >= minFunctionPatternMatches {
+		lineNum, _ := strconv.Atoi(matches[3])
+		colNum, _ := strconv.Atoi(matches[4])
+		functionName := strings.TrimPrefix(strings.TrimSpace(matches[1]), "at ")
+
+		frame := models.StackFrame{
+			Function:       functionName,
+			File:           matches[2],
+			Line:           lineNum,
+			Column:         colNum,
+			CollapsedCount: collapsedCount,
+			IsNative:       isNativeFrame(matches[2]),
+			Raw:            line,
+		}
+		if origFile, origLine, origCol, ok := resolveFrameSourceMap(line); ok {
+			frame.OriginalSource = fmt.Sprintf("%s:%d:%d", origFile, origLine, origCol)
+		}
+		return frame, true
+	}
+
+	if matches := reactFramePattern.FindStringSubmatch(line); len(matches) >= minReactPatternMatches {
+		lineNum, _ := strconv.Atoi(matches[3])
+
+		frame := models.StackFrame{
+			Function:       strings.TrimSpace(matches[1]),
+			File:           matches[2],
+			Line:           lineNum,
+			CollapsedCount: collapsedCount,
+			IsNative:       isNativeFrame(matches[2]),
+			Raw:            line,
+		}
+		if origFile, origLine, origCol, ok := resolveFrameSourceMap(line); ok {
+			frame.OriginalSource = fmt.Sprintf("%s:%d:%d", origFile, origLine, origCol)
+		}
+		return frame, true
+	}
+
+	if matches := nativeFramePattern.FindStringSubmatch(line); matches != nil {
+		return models.StackFrame{
+			Function:       strings.TrimSpace(matches[1]),
+			File:           matches[2],
+			CollapsedCount: collapsedCount,
+			IsNative:       true,
+			Raw:            line,
+		}, true
+	}
+
+	return models.StackFrame{}, false
+}
+
+// cleanGoStackTrace collapses consecutive identical "goroutine N [state]:" blocks
+// (e.g. a pool of workers blocked in the same place) and repeated frame pairs within
+// a single goroutine (e.g. deep recursion), while always preserving the outermost
+// "created by" frame of every goroutine.
+func cleanGoStackTrace(content string) CleanResultPair {
+	blocks := strings.Split(content, "\n\n")
+	var resultBlocks []string
+	var collapsed int
+
+	i := 0
+	for i < len(blocks) {
+		block, frameCollapsed := collapseGoFramePairs(blocks[i])
+		collapsed += frameCollapsed
+
+		sig := goBlockSignature(blocks[i])
+		count := 1
+		j := i + 1
+		for j < len(blocks) && goBlockSignature(blocks[j]) == sig {
+			count++
+			j++
+		}
+
+		if count > 1 {
+			block = annotateGoBlockHeader(block, count)
+			collapsed += count - 1
+		}
+
+		resultBlocks = append(resultBlocks, block)
+		i = j
+	}
+
+	return CleanResultPair{Content: strings.Join(resultBlocks, "\n\n"), Removed: collapsed}
+}
+
+// goBlockSignature normalizes a goroutine block for duplicate-block comparison by
+// dropping the goroutine ID/state and any hex return-address offsets, both of which
+// vary even between otherwise-identical goroutines.
+func goBlockSignature(block string) string {
+	var sig strings.Builder
+	for _, line := range strings.Split(block, "\n") {
+		switch {
+		case goroutineHeaderPattern.MatchString(line):
+			sig.WriteString("goroutine [state]\n")
+		case goLocationPattern.MatchString(line):
+			loc := goLocationPattern.FindStringSubmatch(line)
+			fmt.Fprintf(&sig, "\t%s:%s\n", loc[1], loc[2])
+		default:
+			sig.WriteString(line)
+			sig.WriteString("\n")
+		}
+	}
+	return sig.String()
+}
+
+// annotateGoBlockHeader marks a collapsed block's goroutine header with how many
+// identical goroutines it stands in for.
+func annotateGoBlockHeader(block string, count int) string {
+	lines := strings.Split(block, "\n")
+	for i, line := range lines {
+		if goroutineHeaderPattern.MatchString(line) {
+			lines[i] = fmt.Sprintf("%s // [x%d]", line, count)
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseGoFramePairs collapses consecutive duplicate (function, location) frame
+// pairs within a single goroutine block - e.g. deep recursion - while always keeping
+// the trailing "created by" frame intact.
+func collapseGoFramePairs(block string) (string, int) {
+	lines := strings.Split(block, "\n")
+
+	createdByIdx := -1
+	for i, line := range lines {
+		if goCreatedByPattern.MatchString(line) {
+			createdByIdx = i
+			break
+		}
+	}
+
+	var out []string
+	var collapsed int
+	var lastPair string
+
+	i := 0
+	for i < len(lines) {
+		if createdByIdx != -1 && i >= createdByIdx {
+			out = append(out, lines[i:]...)
+			break
+		}
+
+		if i+1 < len(lines) && goLocationPattern.MatchString(lines[i+1]) {
+			loc := goLocationPattern.FindStringSubmatch(lines[i+1])
+			pair := fmt.Sprintf("%s|%s:%s", strings.TrimSpace(lines[i]), loc[1], loc[2])
+
+			if pair == lastPair {
+				collapsed++
+				i += 2
+				continue
+			}
+
+			lastPair = pair
+			out = append(out, lines[i], lines[i+1])
+			i += 2
+			continue
+		}
+
+		out = append(out, lines[i])
+		lastPair = ""
+		i++
+	}
+
+	return strings.Join(out, "\n"), collapsed
+}
+
+// pythonBurstKeep is how many occurrences of an identical, recurring Python stack
+// frame are kept at the head and tail of a collapsed burst.
+const pythonBurstKeep = 2
+
+// cleanPythonStackTrace collapses bursts of identical "File ..." frames produced by
+// mutually recursive calls, keeping the head and tail of each burst so the recursion
+// is still visible without repeating every intermediate frame. Chained-exception
+// separators ("During handling of the above exception...") are left untouched.
+func cleanPythonStackTrace(content string) CleanResultPair {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var collapsed int
+
+	i := 0
+	for i < len(lines) {
+		if !pythonFileLinePattern.MatchString(lines[i]) {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		frame, frameLen := pythonFrame(lines, i)
+		frameText := strings.Join(frame, "\n")
+
+		repeats := 1
+		j := i + frameLen
+		for j+frameLen <= len(lines) {
+			next, nextLen := pythonFrame(lines, j)
+			if nextLen != frameLen || strings.Join(next, "\n") != frameText {
+				break
+			}
+			repeats++
+			j += frameLen
+		}
+
+		if repeats > pythonBurstKeep*2 {
+			for k := 0; k < pythonBurstKeep; k++ {
+				out = append(out, frame...)
+			}
+
+			skipped := repeats - pythonBurstKeep*2
+			word := "frame"
+			if skipped != 1 {
+				word = "frames"
+			}
+			out = append(out, fmt.Sprintf("  // [... %d more identical %s ...]", skipped, word))
+
+			for k := 0; k < pythonBurstKeep; k++ {
+				out = append(out, frame...)
+			}
+
+			collapsed += skipped
+		} else {
+			for k := 0; k < repeats; k++ {
+				out = append(out, frame...)
+			}
+		}
+
+		i = j
+	}
+
+	return CleanResultPair{Content: strings.Join(out, "\n"), Removed: collapsed}
+}
+
+// pythonFrame returns the lines comprising the Python stack frame starting at index i
+// (the "File ..." line plus its immediately-following indented source line, if any)
+// along with its length.
+func pythonFrame(lines []string, i int) ([]string, int) {
+	length := 1
+	if i+1 < len(lines) && isPythonSourceLine(lines[i+1]) {
+		length = 2
+	}
+	return lines[i : i+length], length
+}
+
+// isPythonSourceLine reports whether line looks like the source snippet Python prints
+// underneath a "File ..." frame line, rather than another frame or traceback header.
+func isPythonSourceLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return false
+	}
+	return !pythonFileLinePattern.MatchString(line) &&
+		!pythonTracebackHeaderPattern.MatchString(trimmed) &&
+		!pythonChainedExceptionPattern.MatchString(trimmed)
+}
+
+// javaBurstKeep is how many occurrences of an identical, recurring Java/Kotlin stack
+// frame are kept at the head and tail of a collapsed burst.
+const javaBurstKeep = 2
+
+// cleanJavaStackTrace collapses bursts of identical "at ..." frame lines produced by
+// tight recursion, keeping the head and tail of each burst. "Caused by:" sections and
+// Java's own "... N more" elision lines are left untouched, since they already
+// represent the JVM's native compaction of shared frames across chained exceptions.
+func cleanJavaStackTrace(content string) CleanResultPair {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var collapsed int
+
+	i := 0
+	for i < len(lines) {
+		if !javaFramePattern.MatchString(lines[i]) {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(lines) && lines[j] == lines[i] {
+			j++
+		}
+		repeats := j - i
+
+		if repeats > javaBurstKeep*2 {
+			out = append(out, lines[i:i+javaBurstKeep]...)
+
+			skipped := repeats - javaBurstKeep*2
+			word := "frame"
+			if skipped != 1 {
+				word = "frames"
+			}
+			out = append(out, fmt.Sprintf("  // [... %d more identical %s ...]", skipped, word))
+
+			out = append(out, lines[j-javaBurstKeep:j]...)
+			collapsed += skipped
+		} else {
+			out = append(out, lines[i:j]...)
+		}
+
+		i = j
+	}
+
+	return CleanResultPair{Content: strings.Join(out, "\n"), Removed: collapsed}
+}
+
+// dotnetBurstKeep is how many occurrences of an identical, recurring .NET stack frame
+// are kept at the head and tail of a collapsed burst.
+const dotnetBurstKeep = 2
+
+// cleanDotNetStackTrace collapses bursts of identical "at ... in File.cs:line N" frame
+// lines produced by tight recursion, keeping the head and tail of each burst.
+func cleanDotNetStackTrace(content string) CleanResultPair {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var collapsed int
+
+	i := 0
+	for i < len(lines) {
+		if !dotnetFramePattern.MatchString(lines[i]) {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(lines) && lines[j] == lines[i] {
+			j++
+		}
+		repeats := j - i
+
+		if repeats > dotnetBurstKeep*2 {
+			out = append(out, lines[i:i+dotnetBurstKeep]...)
+
+			skipped := repeats - dotnetBurstKeep*2
+			word := "frame"
+			if skipped != 1 {
+				word = "frames"
+			}
+			out = append(out, fmt.Sprintf("  // [... %d more identical %s ...]", skipped, word))
+
+			out = append(out, lines[j-dotnetBurstKeep:j]...)
+			collapsed += skipped
+		} else {
+			out = append(out, lines[i:j]...)
+		}
+
+		i = j
+	}
+
+	return CleanResultPair{Content: strings.Join(out, "\n"), Removed: collapsed}
+}
+
+// rubyBurstKeep is how many occurrences of an identical, recurring Ruby backtrace
+// frame are kept at the head and tail of a collapsed burst.
+const rubyBurstKeep = 2
+
+// cleanRubyStackTrace collapses bursts of identical "from file.rb:N:in `method'" frame
+// lines produced by tight recursion, keeping the head and tail of each burst.
+func cleanRubyStackTrace(content string) CleanResultPair {
+	lines := strings.Split(content, "\n")
+	var out []string
+	var collapsed int
+
+	i := 0
+	for i < len(lines) {
+		if !rubyFramePattern.MatchString(strings.TrimSpace(lines[i])) {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(lines) && lines[j] == lines[i] {
+			j++
+		}
+		repeats := j - i
+
+		if repeats > rubyBurstKeep*2 {
+			out = append(out, lines[i:i+rubyBurstKeep]...)
+
+			skipped := repeats - rubyBurstKeep*2
+			word := "frame"
+			if skipped != 1 {
+				word = "frames"
+			}
+			out = append(out, fmt.Sprintf("  // [... %d more identical %s ...]", skipped, word))
+
+			out = append(out, lines[j-rubyBurstKeep:j]...)
+			collapsed += skipped
+		} else {
+			out = append(out, lines[i:j]...)
+		}
+
+		i = j
+	}
+
+	return CleanResultPair{Content: strings.Join(out, "\n"), Removed: collapsed}
 }
 
 // extractFrameSignature creates a unique signature for a stack frame to detect duplicates
 // This function is now optimized to avoid redundant regex compilation and string processing
 func extractFrameSignature(line string) string {
+	// Try user-defined patterns first, so a configured "func"/"file"/"line"
+	// capture takes precedence over the built-in formats below
+	if functionName, fileName, lineNumber, _, ok := matchCustomFrame(line); ok {
+		if functionName == "" {
+			return fmt.Sprintf("%s|%s", fileName, lineNumber)
+		}
+		return fmt.Sprintf("%s|%s|%s", functionName, fileName, lineNumber)
+	}
+
 	// Try standard format first: "at functionName (file.js:123:45)"
 	matches := framePattern.FindStringSubmatch(line)
 	if len(matches) >= minFunctionPatternMatches {
@@ -332,9 +1670,34 @@ func extractFrameSignature(line string) string {
 		return fmt.Sprintf("%s|%s|%s", functionName, fileName, lineNumber)
 	}
 
+	// Try React componentStack format: "in ComponentName (at file.js:12)"
+	if componentMatches := componentStackFramePattern.FindStringSubmatch(line); componentMatches != nil {
+		name, file, lineNumber := componentMatches[1], componentMatches[2], componentMatches[3]
+		if file == "" {
+			// "(created by Parent)" or a bare "in Name" carries no location,
+			// so fall back to the component name alone
+			return fmt.Sprintf("component|%s", name)
+		}
+		return fmt.Sprintf("component|%s|%s|%s", name, file, lineNumber)
+	}
+
+	// Try native frame format: "at functionName (<anonymous>)"
+	if nativeMatches := nativeFramePattern.FindStringSubmatch(line); nativeMatches != nil {
+		functionName := strings.TrimSpace(nativeMatches[1])
+		return fmt.Sprintf("%s|%s", functionName, nativeMatches[2])
+	}
+
 	return line // Fallback to entire line if parsing fails
 }
 
+// isNativeFrame reports whether a frame's file names the JS engine's native
+// code rather than a source file the user could open, e.g.
+// "at Array.forEach (<anonymous>)" or "at Array.forEach (native)".
+func isNativeFrame(file string) bool {
+	file = strings.TrimSpace(file)
+	return file == "<anonymous>" || file == "native" || file == "[native code]"
+}
+
 // isReactInternalFunction determines if a function is a React internal function
 // that should have its line numbers ignored for duplicate detection
 func isReactInternalFunction(functionName, fileName string) bool {
@@ -365,6 +1728,35 @@ func isReactInternalFunction(functionName, fileName string) bool {
 	return false
 }
 
+// reactInternalComponentNames lists componentStack entries that name a React
+// internal wrapper rather than a component the user actually wrote. These are
+// skipped when picking the "deepest" component to surface in ErrorInfo.Component,
+// since "Suspense" or "ForwardRef" tells a user nothing about where their own
+// code lives.
+var reactInternalComponentNames = map[string]bool{
+	"Suspense":         true,
+	"SuspenseList":     true,
+	"ErrorBoundary":    true,
+	"ForwardRef":       true,
+	"Fragment":         true,
+	"StrictMode":       true,
+	"Profiler":         true,
+	"Memo":             true,
+	"Context.Provider": true,
+	"Context.Consumer": true,
+	"Lazy":             true,
+}
+
+// extractComponentStackName returns the component name from a componentStack
+// line (see componentStackFramePattern), or "" if line isn't one.
+func extractComponentStackName(line string) string {
+	matches := componentStackFramePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
 // extractFrameSignatureForStandardFormat handles standard format frame signatures
 func extractFrameSignatureForStandardFormat(functionName, fileName, lineNumber string) string {
 	// For React internal functions, use function + file (ignoring line) to detect duplicates
@@ -378,6 +1770,23 @@ func extractFrameSignatureForStandardFormat(functionName, fileName, lineNumber s
 // ExtractErrorInfo extracts structured information from a stack trace for analysis.
 // This function only parses and analyzes the content - it does not modify the original clipboard content.
 func ExtractErrorInfo(content string) *models.ErrorInfo {
+	return extractErrorInfo(content, buildCodeFrame)
+}
+
+// extractErrorInfoWithOptions is ExtractErrorInfo with codeframe rendering
+// driven by opts (see CleanResultWithOptions) instead of the package-level
+// codeFrameContextLines/codeframe.EnableColor knobs.
+func extractErrorInfoWithOptions(content string, opts CleanOptions) *models.ErrorInfo {
+	return extractErrorInfo(content, func(line string) string {
+		return buildCodeFrameWithOptions(line, opts)
+	})
+}
+
+// extractErrorInfo is ExtractErrorInfo's shared implementation, parameterized
+// by codeFrameFn so ExtractErrorInfo and extractErrorInfoWithOptions can
+// render a codeframe differently (package-level knobs vs. a CleanOptions)
+// without duplicating the rest of the extraction logic.
+func extractErrorInfo(content string, codeFrameFn func(line string) string) *models.ErrorInfo {
 	if !IsStackTrace(content) {
 		return nil
 	}
@@ -387,6 +1796,8 @@ func ExtractErrorInfo(content string) *models.ErrorInfo {
 	var stackFrames []string
 	var source string
 	var component string
+	var componentStackName string
+	var codeFrame string
 
 	// Extract stack frames and look for React component info
 	for _, line := range lines {
@@ -396,9 +1807,18 @@ func ExtractErrorInfo(content string) *models.ErrorInfo {
 			continue
 		}
 
-		// Try to extract source information
+		// Try to extract source information, preferring the original (pre-bundling)
+		// location when a source map resolves this frame
 		if source == "" {
-			source = extractSourceInfo(line)
+			if origFile, origLine, origCol, ok := resolveFrameSourceMap(line); ok {
+				source = fmt.Sprintf("%s:%d:%d", origFile, origLine, origCol)
+			} else {
+				source = extractSourceInfo(line)
+			}
+
+			if codeFrame == "" {
+				codeFrame = codeFrameFn(line)
+			}
 		}
 
 		// Look for React component names
@@ -406,14 +1826,52 @@ func ExtractErrorInfo(content string) *models.ErrorInfo {
 			component = extractComponentInfo(line)
 		}
 
+		// A componentStack lists the failing component first, so the first
+		// non-internal name we see is the deepest user component - prefer it
+		// over anything extractComponentInfo found from a lifecycle method call
+		if componentStackName == "" {
+			if name := extractComponentStackName(line); name != "" && !reactInternalComponentNames[name] {
+				componentStackName = name
+			}
+		}
+
 		stackFrames = append(stackFrames, originalLine)
 	}
 
+	if componentStackName != "" {
+		component = componentStackName
+	}
+
 	return &models.ErrorInfo{
 		Message:   message,
 		Stack:     stackFrames,
 		Source:    source,
 		Component: component,
+		CodeFrame: codeFrame,
+	}
+}
+
+// componentStackSeparator marks the boundary between a JS engine stack and a
+// paired React componentStack in the string JoinStackAndComponentStack builds.
+const componentStackSeparator = "\n--- Component Stack ---\n"
+
+// JoinStackAndComponentStack concatenates a JS engine stack (error.stack) and
+// its paired React componentStack (error.componentStack from an error
+// boundary, or the one React DevTools shows) into a single normalized trace,
+// so a user can paste both blobs from a React error and have CleanResult
+// dedupe and analyze them together. Either half may be empty; joining with an
+// empty componentStack just returns stack unchanged, and vice versa.
+func JoinStackAndComponentStack(stack, componentStack string) string {
+	stack = strings.TrimRight(stack, "\n")
+	componentStack = strings.TrimRight(componentStack, "\n")
+
+	switch {
+	case stack == "":
+		return componentStack
+	case componentStack == "":
+		return stack
+	default:
+		return stack + componentStackSeparator + componentStack
 	}
 }
 
@@ -433,17 +1891,51 @@ func CleanResult(content string) models.CleanResult {
 	linesBefore := strings.Count(original, "\n") + 1 // +1 for the last line if no trailing newline
 	linesAfter := strings.Count(cleaned, "\n") + 1
 
-	var frames []models.StackFrame
 	errorInfo := ExtractErrorInfo(content)
 
 	return models.CleanResult{
-		Original:    original,
-		Cleaned:     cleaned,
-		Removed:     removed,
-		BytesSaved:  bytesSaved,
-		LinesBefore: linesBefore,
-		LinesAfter:  linesAfter,
-		Frames:      frames,
-		ErrorInfo:   errorInfo,
+		Original:      original,
+		Cleaned:       cleaned,
+		Removed:       removed,
+		BytesSaved:    bytesSaved,
+		LinesBefore:   linesBefore,
+		LinesAfter:    linesAfter,
+		Frames:        cleanResult.Frames,
+		ErrorInfo:     errorInfo,
+		Language:      DetectLanguage(content),
+		Cycles:        DetectCycles(FrameSignatures(content)),
+		RemovedGroups: cleanResult.RemovedGroups,
+	}
+}
+
+// CleanResultWithOptions is CleanResult with per-call control over codeframe
+// rendering (opts), instead of the package-level SetCodeFrameContextLines/
+// codeframe.EnableColor knobs CleanResult uses. Everything else - detection,
+// cleaning, frame extraction - behaves identically to CleanResult.
+func CleanResultWithOptions(content string, opts CleanOptions) models.CleanResult {
+	original := content
+
+	cleanResult := CleanStackTrace(content)
+	cleaned := cleanResult.Content
+	removed := cleanResult.Removed
+
+	bytesSaved := len(original) - len(cleaned)
+	linesBefore := strings.Count(original, "\n") + 1
+	linesAfter := strings.Count(cleaned, "\n") + 1
+
+	errorInfo := extractErrorInfoWithOptions(content, opts)
+
+	return models.CleanResult{
+		Original:      original,
+		Cleaned:       cleaned,
+		Removed:       removed,
+		BytesSaved:    bytesSaved,
+		LinesBefore:   linesBefore,
+		LinesAfter:    linesAfter,
+		Frames:        cleanResult.Frames,
+		ErrorInfo:     errorInfo,
+		Language:      DetectLanguage(content),
+		Cycles:        DetectCycles(FrameSignatures(content)),
+		RemovedGroups: cleanResult.RemovedGroups,
 	}
 }
@@ -2,8 +2,13 @@ package parser
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
 )
 
 func TestIsStackTrace(t *testing.T) {
@@ -109,8 +114,9 @@ func TestCleanStackTrace(t *testing.T) {
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
     at ReactCompositeComponent._renderValidatedComponent (react-dom.development.js:185:13)`,
-			expected: `// Removed 2 repetitive stack frame(s)
-Error: Failed to render
+			expected: `Error: Failed to render
+    at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
+// Recursion ReactErrorUtils.invokeGuardedCallback repeated 3 times
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
     at ReactCompositeComponent._renderValidatedComponent (react-dom.development.js:185:13)`,
 		},
@@ -137,9 +143,11 @@ Error: Failed to render
     at UserProfile.render (UserProfile.js:45:12)
     at ReactCompositeComponent._renderValidatedComponent (react-dom.development.js:185:13)
     at UserProfile.render (UserProfile.js:45:12)`,
-			expected: `// Removed 2 repetitive stack frame(s)
+			expected: `// Removed 1 repetitive stack frame(s)
 TypeError: Cannot read property 'name' of undefined
     at UserProfile.render (UserProfile.js:45:12)
+// Recursion UserProfile.render repeated 2 times
+    at UserProfile.render (UserProfile.js:45:12)
     at ReactCompositeComponent._renderValidatedComponent (react-dom.development.js:185:13)`,
 		},
 		{
@@ -149,8 +157,10 @@ react_stack_bottom_frame @ react-dom-client.development.js:23669
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
 react_stack_bottom_frame @ react-dom-client.development.js:23669
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)`,
-			expected: `// Removed 2 repetitive stack frame(s)
-useAuth.useEffect @ S:\Projects\com.github\PeleOs-LLC\ROK-UI-v2\src\lib\hooks\useAuth.ts:47
+			expected: `useAuth.useEffect @ S:\Projects\com.github\PeleOs-LLC\ROK-UI-v2\src\lib\hooks\useAuth.ts:47
+react_stack_bottom_frame @ react-dom-client.development.js:23669
+    at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)
+// Recursion react_stack_bottom_frame -> ReactErrorUtils.invokeGuardedCallback repeated 2 times
 react_stack_bottom_frame @ react-dom-client.development.js:23669
     at ReactErrorUtils.invokeGuardedCallback (react-dom.development.js:138:15)`,
 		},
@@ -350,9 +360,10 @@ func TestCleanStackTraceReturnsAccurateCount(t *testing.T) {
 
 	result := CleanStackTrace(input)
 
-	// Check that we detected exactly 2 removals (3 duplicates, but we keep the first one)
-	if result.Removed != 2 {
-		t.Errorf("CleanStackTrace.Removed = %d, want 2", result.Removed)
+	// A repeating frame sequence is a recursion cycle: only the middle
+	// repetition (strictly between the preserved first and last) is dropped.
+	if result.Removed != 1 {
+		t.Errorf("CleanStackTrace.Removed = %d, want 1", result.Removed)
 	}
 
 	// Check that cleaned content is different from original
@@ -360,9 +371,9 @@ func TestCleanStackTraceReturnsAccurateCount(t *testing.T) {
 		t.Error("CleanStackTrace.Content should be different from original when duplicates are removed")
 	}
 
-	// Check that the comment mentions the correct number
-	if !strings.Contains(result.Content, "Removed 2 repetitive stack frame(s)") {
-		t.Error("CleanStackTrace.Content should contain comment with correct removal count")
+	// Check that the comment names the repeated frame and repetition count
+	if !strings.Contains(result.Content, "// Recursion ReactErrorUtils.invokeGuardedCallback repeated 3 times") {
+		t.Error("CleanStackTrace.Content should contain a recursion marker with the correct repetition count")
 	}
 }
 
@@ -403,8 +414,10 @@ func TestCleanResultStatistics(t *testing.T) {
 		t.Errorf("LinesBefore = %d, want %d", result.LinesBefore, expectedLinesBefore)
 	}
 
-	// After cleaning, should have 3 lines (Error + comment + 1 unique frame)
-	expectedLinesAfter := 3
+	// After cleaning, should have 4 lines (Error + first occurrence + recursion
+	// marker + preserved last occurrence - a 2-repetition cycle, not a plain
+	// dedup, since both occurrences of the frame are identical)
+	expectedLinesAfter := 4
 	if result.LinesAfter != expectedLinesAfter {
 		t.Errorf("LinesAfter = %d, want %d", result.LinesAfter, expectedLinesAfter)
 	}
@@ -478,3 +491,422 @@ func BenchmarkExtractErrorInfo(b *testing.B) {
 		ExtractErrorInfo(input)
 	}
 }
+
+func TestSetCustomPatternsRejectsMalformedPatterns(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	err := SetCustomPatterns("(", minStackLinesForDetection)
+	if err == nil {
+		t.Fatal("expected an error for an unbalanced regex")
+	}
+}
+
+func TestSetCustomPatternsRejectsDuplicateGroupNames(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	err := SetCustomPatterns(`(?P<file>.+):(?P<file>\d+)`, minStackLinesForDetection)
+	if err == nil {
+		t.Fatal("expected an error for a pattern with a duplicate named capture group")
+	}
+}
+
+func TestSetCustomPatternsRejectsOverBudget(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	huge := make([]string, maxCustomPatterns+1)
+	for i := range huge {
+		huge[i] = "x"
+	}
+	if err := SetCustomPatterns(strings.Join(huge, ","), minStackLinesForDetection); err == nil {
+		t.Fatal("expected an error for exceeding maxCustomPatterns")
+	}
+
+	if err := SetCustomPatterns(strings.Repeat("x", maxCustomPatternLength+1), minStackLinesForDetection); err == nil {
+		t.Fatal("expected an error for a pattern exceeding maxCustomPatternLength")
+	}
+}
+
+func TestCustomPatternsFeedFrameExtraction(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	err := SetCustomPatterns(`^(?P<func>\w+) in (?P<file>[\w.]+) at (?P<line>\d+):(?P<col>\d+)$`, minStackLinesForDetection)
+	if err != nil {
+		t.Fatalf("SetCustomPatterns returned error: %v", err)
+	}
+
+	line := "renderWidget in widget.rs at 42:8"
+
+	if got, want := extractFrameSignature(line), "renderWidget|widget.rs|42"; got != want {
+		t.Errorf("extractFrameSignature() = %q, want %q", got, want)
+	}
+	if got, want := extractSourceInfo(line), "widget.rs:42:8"; got != want {
+		t.Errorf("extractSourceInfo() = %q, want %q", got, want)
+	}
+}
+
+func TestCustomPatternsContributeToDetection(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	err := SetCustomPatterns(`^(?P<func>\w+) in (?P<file>[\w.]+) at (?P<line>\d+):(?P<col>\d+)$`, minStackLinesForDetection)
+	if err != nil {
+		t.Fatalf("SetCustomPatterns returned error: %v", err)
+	}
+
+	content := "renderWidget in widget.rs at 42:8\nmain in widget.rs at 10:1"
+	if !isJavaScriptStackTrace(content) {
+		t.Error("expected custom patterns to count toward stack trace detection")
+	}
+}
+
+func TestCustomPatternsPerPatternMinLinesOverridesDefault(t *testing.T) {
+	defer SetCustomPatterns("", minStackLinesForDetection)
+
+	// A single match of a pattern with minLines=1 should be enough to qualify,
+	// even though the package default (minStackLinesForDetection) is 2.
+	err := SetCustomPatterns(`^(?P<func>\w+) in (?P<file>[\w.]+) at (?P<line>\d+):(?P<col>\d+)$=1`, minStackLinesForDetection)
+	if err != nil {
+		t.Fatalf("SetCustomPatterns returned error: %v", err)
+	}
+
+	content := "renderWidget in widget.rs at 42:8"
+	if !isJavaScriptStackTrace(content) {
+		t.Error("expected a single match of a minLines=1 custom pattern to qualify as a stack trace")
+	}
+}
+
+func TestParseCustomPatternSpecsBareEntryUsesDefault(t *testing.T) {
+	specs, err := ParseCustomPatternSpecs(`foo=3,bar`, 7)
+	if err != nil {
+		t.Fatalf("ParseCustomPatternSpecs returned error: %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 specs, got %d", len(specs))
+	}
+	if specs[0].MinLines != 3 {
+		t.Errorf("specs[0].MinLines = %d, want 3", specs[0].MinLines)
+	}
+	if specs[1].MinLines != 7 {
+		t.Errorf("specs[1].MinLines = %d, want the default of 7", specs[1].MinLines)
+	}
+	if specs[0].Priority <= specs[1].Priority {
+		t.Errorf("expected the earlier entry to have higher priority: specs[0].Priority=%d, specs[1].Priority=%d", specs[0].Priority, specs[1].Priority)
+	}
+}
+
+func TestMatchWithTimeoutHandlesLongInput(t *testing.T) {
+	pattern := regexp.MustCompile(`(?P<file>\w+\.go):(?P<line>\d+)`)
+	longLine := strings.Repeat("x", maxLineLength) + " main.go:1"
+
+	if !matchWithTimeout(pattern, longLine) {
+		t.Error("expected a match against a long but well-formed line")
+	}
+}
+
+func TestIsStackTraceDetectsComponentStack(t *testing.T) {
+	componentStack := "The above error occurred in the <Button> component:\n" +
+		"    in Button (at App.js:12)\n" +
+		"    in ErrorBoundary (created by App)\n" +
+		"    in App (at index.js:7)"
+
+	if !IsStackTrace(componentStack) {
+		t.Errorf("IsStackTrace(%q) = false, want true", componentStack)
+	}
+}
+
+func TestCleanStackTraceDedupsComponentStackFrames(t *testing.T) {
+	input := "The above error occurred in the <Button> component:\n" +
+		"    in Button (at App.js:12)\n" +
+		"    in Button (at App.js:12)\n" +
+		"    in App (at index.js:7)"
+
+	result := CleanStackTrace(input)
+
+	// A length-1 sequence repeating twice is a recursion cycle (first and
+	// last occurrence preserved, nothing strictly in between to drop).
+	if result.Removed != 0 {
+		t.Errorf("CleanStackTrace(%q).Removed = %d, want 0", input, result.Removed)
+	}
+	if strings.Count(result.Content, "in Button") != 2 {
+		t.Errorf("CleanStackTrace(%q).Content = %q, want both Button frames preserved around a recursion marker", input, result.Content)
+	}
+	if !strings.Contains(result.Content, "// Recursion Button repeated 2 times") {
+		t.Errorf("CleanStackTrace(%q).Content = %q, want a recursion marker", input, result.Content)
+	}
+}
+
+func TestExtractErrorInfoSurfacesDeepestComponentStackComponent(t *testing.T) {
+	input := "Error: Cannot read properties of undefined\n" +
+		"    at Button (Button.js:10:5)\n" +
+		"The above error occurred in the <Button> component:\n" +
+		"    in Button (at App.js:12)\n" +
+		"    in Suspense (created by App)\n" +
+		"    in ErrorBoundary (at App.js:3)\n" +
+		"    in App (at index.js:7)"
+
+	result := ExtractErrorInfo(input)
+	if result == nil {
+		t.Fatalf("ExtractErrorInfo(%q) = nil, want a non-nil result", input)
+	}
+	if result.Component != "Button" {
+		t.Errorf("ExtractErrorInfo(%q).Component = %q, want %q (the deepest non-internal component, skipping Suspense/ErrorBoundary)", input, result.Component, "Button")
+	}
+}
+
+func TestCleanResultWithOptionsRendersCodeFrameFromRoot(t *testing.T) {
+	dir := t.TempDir()
+	source := "function Button() {\n  return undefined.prop\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "Button.js"), []byte(source), 0o644); err != nil {
+		t.Fatalf("failed to write test source file: %v", err)
+	}
+
+	input := "TypeError: Cannot read properties of undefined\n    at Button (Button.js:2:10)"
+	result := CleanResultWithOptions(input, CleanOptions{Root: dir, ContextLines: 1})
+
+	if result.ErrorInfo == nil {
+		t.Fatalf("CleanResultWithOptions(%q, ...).ErrorInfo = nil, want non-nil", input)
+	}
+	if !strings.Contains(result.ErrorInfo.CodeFrame, "undefined.prop") {
+		t.Errorf("CleanResultWithOptions(%q, ...).ErrorInfo.CodeFrame = %q, want it to contain the error line", input, result.ErrorInfo.CodeFrame)
+	}
+}
+
+func TestCleanResultWithOptionsSkipsBundledAssets(t *testing.T) {
+	input := "TypeError: x is not a function\n    at f (node_modules/some-lib/dist/index.js:10:5)"
+	result := CleanResultWithOptions(input, CleanOptions{ContextLines: 2})
+
+	if result.ErrorInfo == nil {
+		t.Fatalf("CleanResultWithOptions(%q, ...).ErrorInfo = nil, want non-nil", input)
+	}
+	if result.ErrorInfo.CodeFrame != "" {
+		t.Errorf("CleanResultWithOptions(%q, ...).ErrorInfo.CodeFrame = %q, want \"\" for a node_modules path", input, result.ErrorInfo.CodeFrame)
+	}
+}
+
+func TestCleanResultWithOptionsContextLinesZeroDisablesCodeFrame(t *testing.T) {
+	input := "TypeError: x is not a function\n    at Button (Button.js:2:10)"
+	result := CleanResultWithOptions(input, CleanOptions{})
+
+	if result.ErrorInfo != nil && result.ErrorInfo.CodeFrame != "" {
+		t.Errorf("CleanResultWithOptions(%q, CleanOptions{}).ErrorInfo.CodeFrame = %q, want \"\" when ContextLines is 0", input, result.ErrorInfo.CodeFrame)
+	}
+}
+
+// fakeSourceMapResolver resolves every frame in files to the fixed original
+// position, so tests don't need a real ".map" file on disk.
+type fakeSourceMapResolver struct {
+	files map[string]struct {
+		file string
+		line int
+		col  int
+	}
+}
+
+func (f *fakeSourceMapResolver) Resolve(file string, _, _ int) (string, int, int, bool) {
+	mapped, ok := f.files[file]
+	if !ok {
+		return "", 0, 0, false
+	}
+	return mapped.file, mapped.line, mapped.col, true
+}
+
+func TestCleanStackTraceWithSourceMapsResolvesOriginalPosition(t *testing.T) {
+	resolver := &fakeSourceMapResolver{files: map[string]struct {
+		file string
+		line int
+		col  int
+	}{
+		"main.abcd1234.js": {file: "src/Foo.tsx", line: 42, col: 8},
+	}}
+
+	input := "TypeError: x is not a function\n    at f (main.abcd1234.js:1:99999)"
+	result := CleanStackTraceWithSourceMaps(input, resolver)
+
+	if !strings.Contains(result.Content, "src/Foo.tsx:42:8") {
+		t.Errorf("CleanStackTraceWithSourceMaps(%q, ...).Content = %q, want it annotated with the resolved original position", input, result.Content)
+	}
+	if len(result.Frames) != 1 || result.Frames[0].OriginalSource != "src/Foo.tsx:42:8" {
+		t.Errorf("CleanStackTraceWithSourceMaps(%q, ...).Frames = %+v, want OriginalSource %q", input, result.Frames, "src/Foo.tsx:42:8")
+	}
+
+	// The package-level resolver (none installed in this test) must be
+	// unaffected once the call returns.
+	if sourceMapResolver != nil {
+		t.Error("CleanStackTraceWithSourceMaps left a resolver installed after returning")
+	}
+}
+
+func TestCleanResultWithSourceMapsResolvesErrorInfoSource(t *testing.T) {
+	resolver := &fakeSourceMapResolver{files: map[string]struct {
+		file string
+		line int
+		col  int
+	}{
+		"main.abcd1234.js": {file: "src/Foo.tsx", line: 42, col: 8},
+	}}
+
+	input := "TypeError: x is not a function\n    at f (main.abcd1234.js:1:99999)"
+	result := CleanResultWithSourceMaps(input, resolver)
+
+	if result.ErrorInfo == nil || result.ErrorInfo.Source != "src/Foo.tsx:42:8" {
+		t.Errorf("CleanResultWithSourceMaps(%q, ...).ErrorInfo = %+v, want Source %q", input, result.ErrorInfo, "src/Foo.tsx:42:8")
+	}
+}
+
+func TestCleanStackTracePopulatesNativeAndRawFrameFields(t *testing.T) {
+	input := "TypeError: undefined is not a function\n" +
+		"    at Array.forEach (<anonymous>)\n" +
+		"    at Button (Button.js:10:5)"
+
+	result := CleanStackTrace(input)
+	if len(result.Frames) != 2 {
+		t.Fatalf("CleanStackTrace(%q).Frames = %+v, want 2 frames", input, result.Frames)
+	}
+
+	native := result.Frames[0]
+	if !native.IsNative {
+		t.Errorf("Frames[0].IsNative = false, want true for a %q frame", native.File)
+	}
+	if native.Raw != "    at Array.forEach (<anonymous>)" {
+		t.Errorf("Frames[0].Raw = %q, want the original unannotated line", native.Raw)
+	}
+
+	user := result.Frames[1]
+	if user.IsNative {
+		t.Errorf("Frames[1].IsNative = true, want false for a user source file")
+	}
+}
+
+func TestDetectCyclesSimpleRepeat(t *testing.T) {
+	cycles := DetectCycles([]string{"A", "A", "A"})
+
+	if len(cycles) != 1 {
+		t.Fatalf("DetectCycles = %+v, want 1 cycle", cycles)
+	}
+	want := models.CycleInfo{StartFrame: 0, Length: 1, Repetitions: 3}
+	if cycles[0] != want {
+		t.Errorf("cycles[0] = %+v, want %+v", cycles[0], want)
+	}
+}
+
+func TestDetectCyclesAlternatingPair(t *testing.T) {
+	cycles := DetectCycles([]string{"A", "B", "A", "B", "A", "B"})
+
+	if len(cycles) != 1 {
+		t.Fatalf("DetectCycles = %+v, want 1 cycle", cycles)
+	}
+	want := models.CycleInfo{StartFrame: 0, Length: 2, Repetitions: 3}
+	if cycles[0] != want {
+		t.Errorf("cycles[0] = %+v, want %+v", cycles[0], want)
+	}
+}
+
+func TestDetectCyclesOnlyInnerCycleReported(t *testing.T) {
+	cycles := DetectCycles([]string{"A", "B", "C", "B", "C", "B", "C", "D"})
+
+	if len(cycles) != 1 {
+		t.Fatalf("DetectCycles = %+v, want exactly one cycle (the inner B/C run)", cycles)
+	}
+	want := models.CycleInfo{StartFrame: 1, Length: 2, Repetitions: 3}
+	if cycles[0] != want {
+		t.Errorf("cycles[0] = %+v, want %+v", cycles[0], want)
+	}
+}
+
+func TestCollapseRecursionCyclesSimpleRepeat(t *testing.T) {
+	input := `RangeError: Maximum call stack size exceeded
+    at recurse (recurse.js:3:5)
+    at recurse (recurse.js:3:5)
+    at recurse (recurse.js:3:5)`
+
+	collapsed, cycles := CollapseRecursionCycles(input)
+
+	if len(cycles) != 1 || cycles[0].Repetitions != 3 {
+		t.Fatalf("cycles = %+v, want one cycle repeated 3 times", cycles)
+	}
+	if strings.Count(collapsed, "at recurse (recurse.js:3:5)") != 2 {
+		t.Errorf("collapsed = %q, want exactly 2 surviving occurrences (first and last)", collapsed)
+	}
+	if !strings.Contains(collapsed, "// Recursion recurse repeated 3 times") {
+		t.Errorf("collapsed = %q, want a recursion marker", collapsed)
+	}
+}
+
+func TestCollapseRecursionCyclesAlternatingPair(t *testing.T) {
+	input := `RangeError: Maximum call stack size exceeded
+    at a (mutual.js:1:1)
+    at b (mutual.js:2:1)
+    at a (mutual.js:1:1)
+    at b (mutual.js:2:1)
+    at a (mutual.js:1:1)
+    at b (mutual.js:2:1)`
+
+	collapsed, cycles := CollapseRecursionCycles(input)
+
+	if len(cycles) != 1 || cycles[0].Length != 2 || cycles[0].Repetitions != 3 {
+		t.Fatalf("cycles = %+v, want one length-2 cycle repeated 3 times", cycles)
+	}
+	if strings.Count(collapsed, "at a (mutual.js:1:1)") != 2 || strings.Count(collapsed, "at b (mutual.js:2:1)") != 2 {
+		t.Errorf("collapsed = %q, want exactly 2 occurrences each of a and b (first and last)", collapsed)
+	}
+	if !strings.Contains(collapsed, "// Recursion a -> b repeated 3 times") {
+		t.Errorf("collapsed = %q, want a recursion marker naming both frames", collapsed)
+	}
+}
+
+func TestCollapseRecursionCyclesOnlyInnerCycleCollapsed(t *testing.T) {
+	input := `Error: deep call
+    at a (deep.js:1:1)
+    at b (deep.js:2:1)
+    at c (deep.js:3:1)
+    at b (deep.js:2:1)
+    at c (deep.js:3:1)
+    at b (deep.js:2:1)
+    at c (deep.js:3:1)
+    at d (deep.js:4:1)`
+
+	collapsed, cycles := CollapseRecursionCycles(input)
+
+	if len(cycles) != 1 || cycles[0].StartFrame != 1 || cycles[0].Length != 2 || cycles[0].Repetitions != 3 {
+		t.Fatalf("cycles = %+v, want only the inner b/c cycle", cycles)
+	}
+	if strings.Count(collapsed, "at a (deep.js:1:1)") != 1 || strings.Count(collapsed, "at d (deep.js:4:1)") != 1 {
+		t.Errorf("collapsed = %q, want the non-cyclic frames untouched", collapsed)
+	}
+	if strings.Count(collapsed, "at b (deep.js:2:1)") != 2 || strings.Count(collapsed, "at c (deep.js:3:1)") != 2 {
+		t.Errorf("collapsed = %q, want exactly 2 occurrences each of b and c (first and last)", collapsed)
+	}
+}
+
+func TestCleanResultPopulatesCycles(t *testing.T) {
+	input := `RangeError: Maximum call stack size exceeded
+    at recurse (recurse.js:3:5)
+    at recurse (recurse.js:3:5)
+    at recurse (recurse.js:3:5)`
+
+	result := CleanResult(input)
+
+	if len(result.Cycles) != 1 || result.Cycles[0].Repetitions != 3 {
+		t.Errorf("CleanResult.Cycles = %+v, want one cycle repeated 3 times", result.Cycles)
+	}
+}
+
+func TestJoinStackAndComponentStack(t *testing.T) {
+	stack := "TypeError: x is not a function\n    at Button (Button.js:10:5)"
+	componentStack := "    in Button (at App.js:12)\n    in App (at index.js:7)"
+
+	joined := JoinStackAndComponentStack(stack, componentStack)
+
+	if !strings.Contains(joined, stack) || !strings.Contains(joined, componentStack) {
+		t.Errorf("JoinStackAndComponentStack(%q, %q) = %q, want both halves present", stack, componentStack, joined)
+	}
+	if joined != stack+componentStackSeparator+componentStack {
+		t.Errorf("JoinStackAndComponentStack(%q, %q) = %q, want a clear separator between the two", stack, componentStack, joined)
+	}
+
+	if got := JoinStackAndComponentStack(stack, ""); got != stack {
+		t.Errorf("JoinStackAndComponentStack(%q, \"\") = %q, want stack unchanged", stack, got)
+	}
+	if got := JoinStackAndComponentStack("", componentStack); got != componentStack {
+		t.Errorf("JoinStackAndComponentStack(\"\", %q) = %q, want componentStack unchanged", componentStack, got)
+	}
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"bufio"
+	"container/list"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxStreamFrameSignatures bounds how many distinct frame signatures
+// StreamClean tracks at once. Beyond this the least-recently-used signature
+// is evicted, trading perfect deduplication of signatures that cycle out of
+// the window for memory that stays constant regardless of input size. Real
+// stack traces have at most a few hundred distinct frame shapes even in
+// multi-hundred-MB logs, so eviction is a pathological-input concern, not a
+// normal-case one.
+const maxStreamFrameSignatures = 10000
+
+// streamScanBufferSize is the initial buffer bufio.Scanner grows from; it's
+// sized for the common case so most lines need no reallocation. The scanner
+// is still capped at maxLineLength, matching isValidContent's per-line limit.
+const streamScanBufferSize = 4 * 1024
+
+// maxDetectionSniffBytes bounds how much of a stream IsStackTraceReader reads
+// before deciding whether content looks like a stack trace. Dialect
+// detection only needs to see the first frame or two, so sniffing a prefix
+// lets detection work on arbitrarily large input without ever holding more
+// than this many bytes in memory.
+const maxDetectionSniffBytes = 64 * 1024
+
+// frameSignatureLRU is a bounded least-recently-used cache from frame
+// signature to occurrence count. StreamClean uses one per pass so memory
+// stays constant no matter how large the input is.
+type frameSignatureLRU struct {
+	capacity int
+	counts   map[string]int
+	order    *list.List
+	elems    map[string]*list.Element
+}
+
+func newFrameSignatureLRU(capacity int) *frameSignatureLRU {
+	return &frameSignatureLRU{
+		capacity: capacity,
+		counts:   make(map[string]int),
+		order:    list.New(),
+		elems:    make(map[string]*list.Element),
+	}
+}
+
+// increment records one more occurrence of signature, evicting the
+// least-recently-used signature first if the cache is full.
+func (l *frameSignatureLRU) increment(signature string) {
+	l.touch(signature)
+	l.counts[signature]++
+}
+
+// get returns the occurrence count recorded for signature, or 0 if it was
+// never seen or has since been evicted.
+func (l *frameSignatureLRU) get(signature string) int {
+	return l.counts[signature]
+}
+
+func (l *frameSignatureLRU) touch(signature string) {
+	if elem, ok := l.elems[signature]; ok {
+		l.order.MoveToFront(elem)
+		return
+	}
+	if l.order.Len() >= l.capacity {
+		l.evictOldest()
+	}
+	elem := l.order.PushFront(signature)
+	l.elems[signature] = elem
+}
+
+func (l *frameSignatureLRU) evictOldest() {
+	oldest := l.order.Back()
+	if oldest == nil {
+		return
+	}
+	signature := oldest.Value.(string)
+	l.order.Remove(oldest)
+	delete(l.elems, signature)
+	delete(l.counts, signature)
+}
+
+// newStreamScanner builds the bufio.Scanner every StreamClean pass uses, so
+// the buffer growth policy stays consistent between passes.
+func newStreamScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, streamScanBufferSize), maxLineLength)
+	return scanner
+}
+
+// StreamClean cleans a stack trace read from r, writing the cleaned output
+// to w, without ever holding the whole input in memory the way
+// CleanStackTrace does. This is what lets multi-hundred-MB logs (CI output,
+// browser session recordings) get cleaned at all, since isValidContent
+// rejects anything over 50MB for the string-based API.
+//
+// When r also implements io.Seeker, StreamClean makes two passes: the first
+// counts frame occurrences so each surviving frame that occurred more than
+// once can be annotated with "// [xN]"; the second emits the cleaned
+// output using those counts. When r can't be rewound (a pipe or a network
+// socket), StreamClean falls back to a single pass that still dedupes
+// repeated frames but can't report how many times each one occurred, since
+// the total isn't known until the stream ends.
+func StreamClean(r io.Reader, w io.Writer) (CleanResultPair, error) {
+	if seeker, ok := r.(io.Seeker); ok {
+		return streamCleanTwoPass(r, seeker, w)
+	}
+	return streamCleanSinglePass(r, w)
+}
+
+func streamCleanTwoPass(r io.Reader, seeker io.Seeker, w io.Writer) (CleanResultPair, error) {
+	counts := newFrameSignatureLRU(maxStreamFrameSignatures)
+	scanner := newStreamScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && isStackFrameLine(line) {
+			counts.increment(extractFrameSignature(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return CleanResultPair{}, fmt.Errorf("stream clean: first pass: %w", err)
+	}
+
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return CleanResultPair{}, fmt.Errorf("stream clean: rewind for second pass: %w", err)
+	}
+
+	return streamEmit(r, w, counts)
+}
+
+func streamCleanSinglePass(r io.Reader, w io.Writer) (CleanResultPair, error) {
+	return streamEmit(r, w, nil)
+}
+
+// streamEmit performs the pass that actually writes cleaned output. When
+// counts is nil (the single-pass fast mode), frames are still deduplicated
+// against what's been seen so far, but occurrence counts and "// [xN]"
+// annotations are unavailable.
+func streamEmit(r io.Reader, w io.Writer, counts *frameSignatureLRU) (CleanResultPair, error) {
+	seen := newFrameSignatureLRU(maxStreamFrameSignatures)
+	scanner := newStreamScanner(r)
+	bufWriter := bufio.NewWriter(w)
+
+	var result CleanResultPair
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || !isStackFrameLine(trimmed) {
+			fmt.Fprintln(bufWriter, line)
+			continue
+		}
+
+		signature := extractFrameSignature(trimmed)
+		if seen.get(signature) > 0 {
+			result.Removed++
+			continue
+		}
+		seen.increment(signature)
+
+		outputLine := line
+		collapsedCount := 0
+		if counts != nil {
+			if count := counts.get(signature); count > 1 {
+				outputLine = fmt.Sprintf("%s // [x%d]", line, count)
+				collapsedCount = count - 1
+			}
+		}
+		fmt.Fprintln(bufWriter, outputLine)
+
+		if frame, ok := buildStackFrame(trimmed, collapsedCount); ok {
+			result.Frames = append(result.Frames, frame)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return CleanResultPair{}, fmt.Errorf("stream clean: %w", err)
+	}
+	if err := bufWriter.Flush(); err != nil {
+		return CleanResultPair{}, fmt.Errorf("stream clean: flush output: %w", err)
+	}
+
+	return result, nil
+}
+
+// IsStackTraceReader reports whether r looks like it starts with a
+// recognized stack trace, reading at most maxDetectionSniffBytes of it.
+// Detection only ever needs to see the first frame or two, so this lets
+// callers classify arbitrarily large input without loading all of it, the
+// same way IsStackTrace's 50MB cap would otherwise require.
+func IsStackTraceReader(r io.Reader) (bool, error) {
+	sniff, err := io.ReadAll(io.LimitReader(r, maxDetectionSniffBytes))
+	if err != nil {
+		return false, fmt.Errorf("stream detect: %w", err)
+	}
+	return IsStackTrace(string(sniff)), nil
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const streamSampleTrace = `Error: something broke
+    at foo (app.js:1:1)
+    at bar (app.js:2:2)
+    at foo (app.js:1:1)
+    at baz (app.js:3:3)
+    at foo (app.js:1:1)`
+
+func TestStreamCleanTwoPassAnnotatesDuplicateFrames(t *testing.T) {
+	r := strings.NewReader(streamSampleTrace)
+	var out bytes.Buffer
+
+	result, err := StreamClean(r, &out)
+	if err != nil {
+		t.Fatalf("StreamClean returned error: %v", err)
+	}
+
+	if result.Removed != 2 {
+		t.Errorf("Removed = %d, want 2", result.Removed)
+	}
+	if !strings.Contains(out.String(), "at foo (app.js:1:1) // [x3]") {
+		t.Errorf("output missing duplicate annotation, got:\n%s", out.String())
+	}
+	if strings.Count(out.String(), "at foo (app.js:1:1)") != 1 {
+		t.Errorf("expected duplicate frame collapsed to a single line, got:\n%s", out.String())
+	}
+}
+
+// nonSeekingReader wraps a strings.Reader but hides its Seek method, so
+// StreamClean is forced onto the single-pass fallback.
+type nonSeekingReader struct {
+	r *strings.Reader
+}
+
+func (n *nonSeekingReader) Read(p []byte) (int, error) {
+	return n.r.Read(p)
+}
+
+func TestStreamCleanSinglePassDedupesWithoutCounts(t *testing.T) {
+	r := &nonSeekingReader{r: strings.NewReader(streamSampleTrace)}
+	var out bytes.Buffer
+
+	result, err := StreamClean(r, &out)
+	if err != nil {
+		t.Fatalf("StreamClean returned error: %v", err)
+	}
+
+	if result.Removed != 2 {
+		t.Errorf("Removed = %d, want 2", result.Removed)
+	}
+	if strings.Contains(out.String(), "[x") {
+		t.Errorf("single-pass mode should not annotate occurrence counts, got:\n%s", out.String())
+	}
+	if strings.Count(out.String(), "at foo (app.js:1:1)") != 1 {
+		t.Errorf("expected duplicate frame collapsed to a single line, got:\n%s", out.String())
+	}
+}
+
+func TestStreamCleanPopulatesFrames(t *testing.T) {
+	r := strings.NewReader(streamSampleTrace)
+	var out bytes.Buffer
+
+	result, err := StreamClean(r, &out)
+	if err != nil {
+		t.Fatalf("StreamClean returned error: %v", err)
+	}
+
+	if len(result.Frames) != 3 {
+		t.Fatalf("Frames = %+v, want 3 entries", result.Frames)
+	}
+	if result.Frames[0].CollapsedCount != 2 {
+		t.Errorf("Frames[0].CollapsedCount = %d, want 2", result.Frames[0].CollapsedCount)
+	}
+}
+
+func TestFrameSignatureLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	lru := newFrameSignatureLRU(2)
+	lru.increment("a")
+	lru.increment("b")
+	lru.increment("a")
+	lru.increment("c") // evicts "b", the least recently used
+
+	if lru.get("a") != 2 {
+		t.Errorf("get(a) = %d, want 2", lru.get("a"))
+	}
+	if lru.get("b") != 0 {
+		t.Errorf("get(b) = %d, want 0 (evicted)", lru.get("b"))
+	}
+	if lru.get("c") != 1 {
+		t.Errorf("get(c) = %d, want 1", lru.get("c"))
+	}
+}
+
+func TestIsStackTraceReaderSniffsPrefix(t *testing.T) {
+	ok, err := IsStackTraceReader(strings.NewReader(streamSampleTrace))
+	if err != nil {
+		t.Fatalf("IsStackTraceReader returned error: %v", err)
+	}
+	if !ok {
+		t.Error("expected stack trace content to be detected")
+	}
+
+	ok, err = IsStackTraceReader(strings.NewReader("just a line of plain log output"))
+	if err != nil {
+		t.Fatalf("IsStackTraceReader returned error: %v", err)
+	}
+	if ok {
+		t.Error("expected plain content not to be detected as a stack trace")
+	}
+}
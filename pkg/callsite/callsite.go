@@ -0,0 +1,81 @@
+// Package callsite lets Go programs capture their own call stack and run it
+// through TraceTrim's cleaning pipeline, so a long-running service can dedupe
+// recursive or repeated frames in its own panic logs before writing them,
+// not just use TraceTrim as a clipboard tool. It's modeled on Node's
+// util.getCallSite().
+package callsite
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"com.github/rethunk-tech/tracetrim/internal/models"
+	"com.github/rethunk-tech/tracetrim/parser"
+)
+
+// goroutineHeader is the header Capture's output is wrapped in so the
+// parser's Go dialect recognizes it; the goroutine number is arbitrary since
+// Capture only ever sees a single goroutine.
+const goroutineHeader = "goroutine 1 [running]:"
+
+// Capture walks the calling goroutine's stack via runtime.Callers and
+// runtime.CallersFrames, returning up to depth frames starting skip frames
+// above Capture's own caller. skip=0 means "start at whoever called Capture".
+func Capture(skip, depth int) []models.StackFrame {
+	if depth <= 0 {
+		return nil
+	}
+
+	pcs := make([]uintptr, depth)
+	// +2 accounts for runtime.Callers' own frame and Capture's frame, so
+	// skip=0 lands on Capture's caller rather than Capture itself.
+	n := runtime.Callers(skip+2, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+	result := make([]models.StackFrame, 0, n)
+	for {
+		frame, more := frames.Next()
+		result = append(result, models.StackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// formatFrame renders frame in the "func(...)\n\tfile:line +0x0" shape Go's
+// own panic output uses, so parser.CleanStackTrace's Go dialect recognizes
+// it. The "+0x0" offset is a placeholder; Capture has no way to recover the
+// real one, and the Go dialect's frame signature ignores it anyway.
+func formatFrame(frame models.StackFrame) string {
+	return fmt.Sprintf("%s(...)\n\t%s:%d +0x0", frame.Function, frame.File, frame.Line)
+}
+
+// CaptureAndClean captures the calling goroutine's stack the same way
+// Capture does, formats it into Go's canonical panic-trace shape, and runs
+// it through parser.CleanStackTrace so recursive or repeated frames collapse
+// exactly like they would in a real panic log.
+func CaptureAndClean(skip, depth int) parser.CleanResultPair {
+	frames := Capture(skip+1, depth)
+	if len(frames) == 0 {
+		return parser.CleanResultPair{}
+	}
+
+	var b strings.Builder
+	b.WriteString(goroutineHeader)
+	b.WriteString("\n")
+	for _, frame := range frames {
+		b.WriteString(formatFrame(frame))
+		b.WriteString("\n")
+	}
+
+	return parser.CleanStackTrace(b.String())
+}
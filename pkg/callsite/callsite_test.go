@@ -0,0 +1,45 @@
+package callsite
+
+import (
+	"strings"
+	"testing"
+
+	"com.github/rethunk-tech/tracetrim/parser"
+)
+
+func recurse(n int, depth int) parser.CleanResultPair {
+	if n == 0 {
+		return CaptureAndClean(0, depth)
+	}
+	return recurse(n-1, depth)
+}
+
+func TestCaptureReturnsCallingFrames(t *testing.T) {
+	frames := Capture(0, 10)
+	if len(frames) == 0 {
+		t.Fatal("expected at least one frame")
+	}
+	if !strings.Contains(frames[0].Function, "TestCaptureReturnsCallingFrames") {
+		t.Errorf("frames[0].Function = %q, want it to mention the calling test", frames[0].Function)
+	}
+	if frames[0].Line == 0 {
+		t.Error("expected a non-zero line number for the calling frame")
+	}
+}
+
+func TestCaptureReturnsNilForNonPositiveDepth(t *testing.T) {
+	if frames := Capture(0, 0); frames != nil {
+		t.Errorf("Capture(0, 0) = %+v, want nil", frames)
+	}
+}
+
+func TestCaptureAndCleanCollapsesRecursiveFrames(t *testing.T) {
+	result := recurse(5, 20)
+
+	if !strings.Contains(result.Content, "goroutine 1 [running]:") {
+		t.Fatalf("expected a goroutine header in cleaned output, got:\n%s", result.Content)
+	}
+	if result.Removed == 0 {
+		t.Error("expected recursive frame pairs to be collapsed, got Removed = 0")
+	}
+}